@@ -0,0 +1,198 @@
+package httpx
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/rs/zerolog"
+	"github.com/vloryan/go-libs/stringx"
+)
+
+// AccessLogEntry describes an answered request.
+type AccessLogEntry struct {
+	Start    time.Time
+	Duration time.Duration
+	Method   string
+	// Path is the path of the request including the query.
+	Path       string
+	Proto      string
+	Status     int
+	Bytes      int64
+	RemoteAddr string
+	User       string
+	UserAgent  string
+	Referer    string
+	RequestID  string
+}
+
+// AccessLogger logs answered requests.
+type AccessLogger interface {
+	LogAccess(entry AccessLogEntry)
+}
+
+// AccessLoggerFunc adapts a function to AccessLogger.
+type AccessLoggerFunc func(entry AccessLogEntry)
+
+func (f AccessLoggerFunc) LogAccess(entry AccessLogEntry) {
+	f(entry)
+}
+
+// newAccessLogEntry describes the request req answered by w.
+func newAccessLogEntry(w *StatusAwareResponseWriter, req *http.Request, start time.Time) AccessLogEntry {
+	status := w.Status()
+	if status == 0 {
+		// net/http answers with 200 if the handler writes nothing
+		status = http.StatusOK
+	}
+	path := req.URL.Path
+	if req.URL.RawQuery != "" {
+		path += "?" + req.URL.RawQuery
+	}
+	user, _, _ := req.BasicAuth()
+	requestID := w.Header().Get(RequestIDHeader)
+	if requestID == "" {
+		requestID = RequestIDFrom(req.Context())
+	}
+	return AccessLogEntry{
+		Start:      start,
+		Duration:   time.Since(start),
+		Method:     req.Method,
+		Path:       path,
+		Proto:      req.Proto,
+		Status:     status,
+		Bytes:      w.BytesWritten(),
+		RemoteAddr: req.RemoteAddr,
+		User:       user,
+		UserAgent:  req.UserAgent(),
+		Referer:    req.Referer(),
+		RequestID:  requestID,
+	}
+}
+
+var defaultAccessLogger = sync.OnceValue(DefaultAccessLogger)
+
+// DefaultAccessLogger returns a ConsoleAccessLogger if stdout is a terminal, a ZerologAccessLogger writing
+// JSON to stdout otherwise.
+func DefaultAccessLogger() AccessLogger {
+	if isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd()) {
+		return NewConsoleAccessLogger(os.Stdout)
+	}
+	return NewZerologAccessLogger(zerolog.New(os.Stdout).With().Timestamp().Logger())
+}
+
+// ZerologAccessLogger logs requests as structured events, answers with 5xx status at error level and all
+// others at info level.
+type ZerologAccessLogger struct {
+	Logger zerolog.Logger
+}
+
+func NewZerologAccessLogger(logger zerolog.Logger) *ZerologAccessLogger {
+	return &ZerologAccessLogger{Logger: logger}
+}
+
+func (l *ZerologAccessLogger) LogAccess(entry AccessLogEntry) {
+	level := zerolog.InfoLevel
+	if entry.Status >= http.StatusInternalServerError {
+		level = zerolog.ErrorLevel
+	}
+	e := l.Logger.WithLevel(level).
+		Str("method", entry.Method).
+		Str("path", entry.Path).
+		Int("status", entry.Status).
+		Dur("duration", entry.Duration).
+		Int64("bytes", entry.Bytes).
+		Str("remote_addr", entry.RemoteAddr).
+		Str("user_agent", entry.UserAgent)
+	if entry.RequestID != "" {
+		e = e.Str("request_id", entry.RequestID)
+	}
+	e.Msg("request")
+}
+
+// CLFAccessLogger writes requests in the Common Log Format, or in the Combined Log Format with referer and
+// user agent if Combined is set.
+type CLFAccessLogger struct {
+	Writer   io.Writer
+	Combined bool
+}
+
+// NewCommonLogFormatAccessLogger creates a logger writing the Common Log Format to w.
+func NewCommonLogFormatAccessLogger(w io.Writer) *CLFAccessLogger {
+	return &CLFAccessLogger{Writer: w}
+}
+
+// NewCombinedLogFormatAccessLogger creates a logger writing the Combined Log Format to w.
+func NewCombinedLogFormatAccessLogger(w io.Writer) *CLFAccessLogger {
+	return &CLFAccessLogger{Writer: w, Combined: true}
+}
+
+func (l *CLFAccessLogger) LogAccess(entry AccessLogEntry) {
+	host := entry.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	bytes := "-"
+	if entry.Bytes > 0 {
+		bytes = strconv.FormatInt(entry.Bytes, 10)
+	}
+	line := fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s`,
+		orDash(host),
+		orDash(entry.User),
+		entry.Start.Format("02/Jan/2006:15:04:05 -0700"),
+		entry.Method,
+		escapeQuotes(entry.Path),
+		entry.Proto,
+		entry.Status,
+		bytes)
+	if l.Combined {
+		line += fmt.Sprintf(` "%s" "%s"`, escapeQuotes(orDash(entry.Referer)), escapeQuotes(orDash(entry.UserAgent)))
+	}
+	_, _ = io.WriteString(l.Writer, line+"\n")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func escapeQuotes(s string) string {
+	return strings.ReplaceAll(s, `"`, `\"`)
+}
+
+// ConsoleAccessLogger writes requests as colored lines for terminals.
+type ConsoleAccessLogger struct {
+	logger *log.Logger
+}
+
+func NewConsoleAccessLogger(w io.Writer) *ConsoleAccessLogger {
+	return &ConsoleAccessLogger{logger: log.New(w, "", log.LstdFlags)}
+}
+
+func (l *ConsoleAccessLogger) LogAccess(entry AccessLogEntry) {
+	var statusText string
+	if entry.Status >= http.StatusOK && entry.Status < http.StatusMultipleChoices {
+		statusText = stringx.FormatColored(stringx.ConsoleColorBgGreen, strconv.Itoa(entry.Status))
+	} else if entry.Status >= http.StatusMultipleChoices && entry.Status < http.StatusBadRequest {
+		statusText = stringx.FormatColored(stringx.ConsoleColorBgGray, strconv.Itoa(entry.Status))
+	} else if entry.Status >= http.StatusBadRequest && entry.Status < http.StatusInternalServerError {
+		statusText = stringx.FormatColored(stringx.ConsoleColorBgYellow, strconv.Itoa(entry.Status))
+	} else {
+		statusText = stringx.FormatColored(stringx.ConsoleColorBgRed, strconv.Itoa(entry.Status))
+	}
+	l.logger.Printf("%s %s %s %s",
+		statusText,
+		stringx.FormatColoredRight(stringx.ConsoleColorReset, entry.Duration.String(), 15),
+		stringx.FormatColoredCenter(stringx.ConsoleColorBgGray, entry.Method, 5),
+		entry.Path)
+}
@@ -0,0 +1,129 @@
+package httpx
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/rs/zerolog"
+)
+
+func TestAccessLoggers(t *testing.T) {
+	entry := AccessLogEntry{
+		Start:      time.Date(2024, 3, 1, 12, 30, 45, 0, time.FixedZone("", 3600)),
+		Duration:   1500 * time.Millisecond,
+		Method:     http.MethodGet,
+		Path:       "/items?page=2",
+		Proto:      "HTTP/1.1",
+		Status:     http.StatusOK,
+		Bytes:      123,
+		RemoteAddr: "192.0.2.1:1234",
+		UserAgent:  `curl/8.0 "x"`,
+		RequestID:  "abc",
+	}
+	tests := []struct {
+		name   string
+		logger func(b *bytes.Buffer) AccessLogger
+		entry  AccessLogEntry
+		want   string
+	}{{
+		name:   "common",
+		logger: func(b *bytes.Buffer) AccessLogger { return NewCommonLogFormatAccessLogger(b) },
+		entry:  entry,
+		want:   `192.0.2.1 - - [01/Mar/2024:12:30:45 +0100] "GET /items?page=2 HTTP/1.1" 200 123` + "\n",
+	}, {
+		name:   "combined",
+		logger: func(b *bytes.Buffer) AccessLogger { return NewCombinedLogFormatAccessLogger(b) },
+		entry:  entry,
+		want:   `192.0.2.1 - - [01/Mar/2024:12:30:45 +0100] "GET /items?page=2 HTTP/1.1" 200 123 "-" "curl/8.0 \"x\""` + "\n",
+	}, {
+		name:   "common without body",
+		logger: func(b *bytes.Buffer) AccessLogger { return NewCommonLogFormatAccessLogger(b) },
+		entry:  AccessLogEntry{Start: entry.Start, Method: http.MethodDelete, Path: "/items/1", Proto: "HTTP/2.0", Status: http.StatusNoContent, RemoteAddr: "192.0.2.1", User: "hans"},
+		want:   `192.0.2.1 - hans [01/Mar/2024:12:30:45 +0100] "DELETE /items/1 HTTP/2.0" 204 -` + "\n",
+	}, {
+		name:   "zerolog",
+		logger: func(b *bytes.Buffer) AccessLogger { return NewZerologAccessLogger(zerolog.New(b)) },
+		entry:  entry,
+		want:   `{"level":"info","method":"GET","path":"/items?page=2","status":200,"duration":1500,"bytes":123,"remote_addr":"192.0.2.1:1234","user_agent":"curl/8.0 \"x\"","request_id":"abc","message":"request"}` + "\n",
+	}, {
+		name:   "zerolog server error",
+		logger: func(b *bytes.Buffer) AccessLogger { return NewZerologAccessLogger(zerolog.New(b)) },
+		entry:  AccessLogEntry{Method: http.MethodGet, Path: "/", Status: http.StatusBadGateway},
+		want:   `{"level":"error","method":"GET","path":"/","status":502,"duration":0,"bytes":0,"remote_addr":"","user_agent":"","message":"request"}` + "\n",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			tt.logger(&b).LogAccess(tt.entry)
+			if diff := cmp.Diff(tt.want, b.String()); diff != "" {
+				t.Errorf("LogAccess() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConsoleAccessLogger(t *testing.T) {
+	var b bytes.Buffer
+	NewConsoleAccessLogger(&b).LogAccess(AccessLogEntry{Method: http.MethodPost, Path: "/items", Status: http.StatusCreated})
+	if got := b.String(); !strings.Contains(got, "201") || !strings.HasSuffix(got, " /items\n") {
+		t.Errorf("LogAccess() = %q", got)
+	}
+}
+
+func TestServer_WithAccessLogger(t *testing.T) {
+	var got []AccessLogEntry
+	srv := NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	srv.Use(RequestID, RealIP(netip.MustParsePrefix("192.0.2.0/24"))).
+		WithAccessLogger(AccessLoggerFunc(func(entry AccessLogEntry) {
+			got = append(got, entry)
+		}))
+	req := httptest.NewRequest(http.MethodPut, "/items?id=1", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	req.Header.Set("User-Agent", "test")
+	req.Header.Set(RequestIDHeader, "abc")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+	want := []AccessLogEntry{{
+		Method:     http.MethodPut,
+		Path:       "/items?id=1",
+		Proto:      "HTTP/1.1",
+		Status:     http.StatusAccepted,
+		Bytes:      5,
+		RemoteAddr: "198.51.100.7",
+		UserAgent:  "test",
+		RequestID:  "abc",
+	}}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(AccessLogEntry{}, "Start", "Duration")); diff != "" {
+		t.Errorf("LogAccess() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServer_WithAccessLogger_Panic(t *testing.T) {
+	var got []AccessLogEntry
+	srv := NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})).WithAccessLogger(AccessLoggerFunc(func(entry AccessLogEntry) {
+		got = append(got, entry)
+	}))
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("ServeHTTP() panic = %v, want boom", p)
+			}
+		}()
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	if len(got) != 1 || got[0].Status != http.StatusInternalServerError {
+		t.Errorf("LogAccess() = %+v, want status 500", got)
+	}
+}
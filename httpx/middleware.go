@@ -0,0 +1,170 @@
+package httpx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Middleware wraps a handler, e.g. to act before and after it or to answer the request itself.
+type Middleware func(next http.Handler) http.Handler
+
+// Chain wraps h by middlewares, the first one is the outermost and runs first.
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// Recoverer answers requests whose handler panics with 500 Internal Server Error and logs the panic with its
+// stack trace. http.ErrAbortHandler is passed on to abort the response.
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		sw := statusAware(w)
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(p)
+			}
+			log.Printf("panic serving %s %s: %v\n%s", req.Method, req.URL.Path, p, debug.Stack())
+			if sw.Status() == 0 {
+				http.Error(sw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(sw, req)
+	})
+}
+
+// RequestIDHeader is the header carrying the request ID.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestID passes the ID of the request header RequestIDHeader, or a generated one if there is none, in the
+// request context and the response header.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestIDFrom returns the request ID set by RequestID, an empty string if there is none.
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RealIP sets the RemoteAddr of requests to the client IP passed by proxies in the headers X-Forwarded-For or
+// X-Real-IP. The headers are only trusted if the request is sent by one of trustedProxies, without them the
+// headers are ignored. X-Forwarded-For is read from the right, skipping trusted proxies.
+func RealIP(trustedProxies ...netip.Prefix) Middleware {
+	trusted := func(addr netip.Addr) bool {
+		for _, p := range trustedProxies {
+			if p.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if peer, err := parseAddr(req.RemoteAddr); err == nil && trusted(peer) {
+				if ip, ok := forwardedIP(req.Header, trusted); ok {
+					req.RemoteAddr = ip.String()
+				}
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// forwardedIP returns the client IP of X-Forwarded-For, or of X-Real-IP if there is none.
+func forwardedIP(header http.Header, trusted func(addr netip.Addr) bool) (netip.Addr, bool) {
+	var hops []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := parseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr
+		if !trusted(addr) {
+			break
+		}
+	}
+	if client.IsValid() {
+		return client, true
+	}
+	if addr, err := parseAddr(strings.TrimSpace(header.Get("X-Real-IP"))); err == nil {
+		return addr, true
+	}
+	return netip.Addr{}, false
+}
+
+// parseAddr parses an IP with or without port.
+func parseAddr(s string) (netip.Addr, error) {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	return netip.ParseAddr(s)
+}
+
+// Timeout answers requests whose handler does not answer within d with 503 Service Unavailable, see
+// http.TimeoutHandler. The context of the request is canceled at the deadline, writes of the handler after it
+// fail with http.ErrHandlerTimeout.
+func Timeout(d time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.TimeoutHandler(next, d, http.StatusText(http.StatusServiceUnavailable)+"\n")
+	}
+}
+
+// MaxBodySize limits request bodies to n bytes. Requests announcing a larger body are answered with
+// 413 Request Entity Too Large, reading beyond the limit fails with http.MaxBytesError.
+func MaxBodySize(n int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.ContentLength > n {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			if req.Body != nil {
+				req.Body = http.MaxBytesReader(w, req.Body, n)
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// statusAware returns w if it tracks the status already, or wraps it.
+func statusAware(w http.ResponseWriter) *StatusAwareResponseWriter {
+	if sw, ok := w.(*StatusAwareResponseWriter); ok {
+		return sw
+	}
+	return &StatusAwareResponseWriter{ResponseWriter: w}
+}
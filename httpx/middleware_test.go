@@ -0,0 +1,225 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChain(t *testing.T) {
+	var got []string
+	record := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				got = append(got, name+" before")
+				next.ServeHTTP(w, req)
+				got = append(got, name+" after")
+			})
+		}
+	}
+	handler := Chain(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		got = append(got, "handler")
+	}), record("first"), record("second"))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	want := []string{"first before", "second before", "handler", "second after", "first after"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Chain() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServer_Use(t *testing.T) {
+	srv := NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(RequestIDFrom(req.Context())))
+	}))
+	srv.WithMiddleware(func(req *http.Request) *http.Request {
+		req.Header.Set(RequestIDHeader, "from-func")
+		return req
+	}).Use(RequestID, Recoverer)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := w.Body.String(); got != "from-func" {
+		t.Errorf("ServeHTTP() body = %v, want from-func", got)
+	}
+	if got := w.Header().Get(RequestIDHeader); got != "from-func" {
+		t.Errorf("ServeHTTP() %s = %v, want from-func", RequestIDHeader, got)
+	}
+}
+
+func TestServer_Use_ChainsOnce(t *testing.T) {
+	chained := 0
+	srv := NewServer(http.NotFoundHandler()).WithAccessLogger(AccessLoggerFunc(func(AccessLogEntry) {}))
+	srv.Use(func(next http.Handler) http.Handler {
+		chained++
+		return next
+	})
+	for range 3 {
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	if chained != 1 {
+		t.Errorf("middleware chained %d times, want 1", chained)
+	}
+}
+
+func TestMiddlewares(t *testing.T) {
+	tests := []struct {
+		name       string
+		middleware Middleware
+		handler    http.HandlerFunc
+		setup      func(req *http.Request)
+		body       string
+		wantStatus int
+		wantBody   string
+		wantHeader map[string]string
+	}{{
+		name:       "recoverer",
+		middleware: Recoverer,
+		handler:    func(http.ResponseWriter, *http.Request) { panic("failed") },
+		wantStatus: http.StatusInternalServerError,
+		wantBody:   "Internal Server Error\n",
+	}, {
+		name:       "recoverer after write",
+		middleware: Recoverer,
+		handler: func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("failed")
+		},
+		wantStatus: http.StatusAccepted,
+	}, {
+		name:       "request id generated",
+		middleware: RequestID,
+		handler: func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte(strings.Repeat("x", len(RequestIDFrom(req.Context())))))
+		},
+		wantStatus: http.StatusOK,
+		wantBody:   strings.Repeat("x", 32),
+	}, {
+		name:       "request id passed",
+		middleware: RequestID,
+		setup:      func(req *http.Request) { req.Header.Set(RequestIDHeader, "abc") },
+		handler:    func(w http.ResponseWriter, req *http.Request) { _, _ = w.Write([]byte(RequestIDFrom(req.Context()))) },
+		wantStatus: http.StatusOK,
+		wantBody:   "abc",
+		wantHeader: map[string]string{RequestIDHeader: "abc"},
+	}, {
+		name:       "real ip forwarded",
+		middleware: RealIP(netip.MustParsePrefix("10.0.0.0/8")),
+		setup: func(req *http.Request) {
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Add("X-Forwarded-For", "1.1.1.1, 2.2.2.2")
+			req.Header.Add("X-Forwarded-For", "10.0.0.2")
+		},
+		handler:    func(w http.ResponseWriter, req *http.Request) { _, _ = w.Write([]byte(req.RemoteAddr)) },
+		wantStatus: http.StatusOK,
+		wantBody:   "2.2.2.2",
+	}, {
+		name:       "real ip header",
+		middleware: RealIP(netip.MustParsePrefix("192.0.2.0/24")),
+		setup: func(req *http.Request) {
+			req.Header.Set("X-Real-IP", "2001:db8::1")
+		},
+		handler:    func(w http.ResponseWriter, req *http.Request) { _, _ = w.Write([]byte(req.RemoteAddr)) },
+		wantStatus: http.StatusOK,
+		wantBody:   "2001:db8::1",
+	}, {
+		name:       "real ip untrusted peer",
+		middleware: RealIP(netip.MustParsePrefix("10.0.0.0/8")),
+		setup: func(req *http.Request) {
+			req.RemoteAddr = "3.3.3.3:1234"
+			req.Header.Set("X-Forwarded-For", "1.1.1.1")
+		},
+		handler:    func(w http.ResponseWriter, req *http.Request) { _, _ = w.Write([]byte(req.RemoteAddr)) },
+		wantStatus: http.StatusOK,
+		wantBody:   "3.3.3.3:1234",
+	}, {
+		name:       "real ip without trusted proxies",
+		middleware: RealIP(),
+		setup: func(req *http.Request) {
+			req.RemoteAddr = "3.3.3.3:1234"
+			req.Header.Set("X-Forwarded-For", "1.1.1.1")
+			req.Header.Set("X-Real-IP", "1.1.1.1")
+		},
+		handler:    func(w http.ResponseWriter, req *http.Request) { _, _ = w.Write([]byte(req.RemoteAddr)) },
+		wantStatus: http.StatusOK,
+		wantBody:   "3.3.3.3:1234",
+	}, {
+		name:       "timeout",
+		middleware: Timeout(time.Millisecond),
+		handler: func(_ http.ResponseWriter, req *http.Request) {
+			<-req.Context().Done()
+		},
+		wantStatus: http.StatusServiceUnavailable,
+		wantBody:   "Service Unavailable\n",
+	}, {
+		name:       "timeout ignoring context",
+		middleware: Timeout(time.Millisecond),
+		handler: func(w http.ResponseWriter, _ *http.Request) {
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusNoContent)
+		},
+		wantStatus: http.StatusServiceUnavailable,
+		wantBody:   "Service Unavailable\n",
+	}, {
+		name:       "within timeout",
+		middleware: Timeout(time.Second),
+		handler:    func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) },
+		wantStatus: http.StatusNoContent,
+	}, {
+		name:       "body too large",
+		middleware: MaxBodySize(4),
+		body:       "too large",
+		handler:    func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) },
+		wantStatus: http.StatusRequestEntityTooLarge,
+		wantBody:   "Request Entity Too Large\n",
+	}, {
+		name:       "body too large streamed",
+		middleware: MaxBodySize(4),
+		body:       "too large",
+		setup:      func(req *http.Request) { req.ContentLength = -1 },
+		handler: func(w http.ResponseWriter, req *http.Request) {
+			var maxErr *http.MaxBytesError
+			if _, err := io.ReadAll(req.Body); errors.As(err, &maxErr) {
+				w.WriteHeader(http.StatusRequestEntityTooLarge)
+			}
+		},
+		wantStatus: http.StatusRequestEntityTooLarge,
+	}, {
+		name:       "body within limit",
+		middleware: MaxBodySize(4),
+		body:       "ok",
+		handler: func(w http.ResponseWriter, req *http.Request) {
+			b, _ := io.ReadAll(req.Body)
+			_, _ = w.Write(b)
+		},
+		wantStatus: http.StatusOK,
+		wantBody:   "ok",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.setup != nil {
+				tt.setup(req)
+			}
+			w := httptest.NewRecorder()
+			tt.middleware(tt.handler).ServeHTTP(w, req.WithContext(context.Background()))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+			for key, want := range tt.wantHeader {
+				if got := w.Header().Get(key); got != want {
+					t.Errorf("header %s = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
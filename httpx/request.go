@@ -22,6 +22,39 @@ func QueryFamily(req *http.Request, familyName string) (map[string]string, bool)
 	return queryFamily(req.URL.Query(), familyName)
 }
 
+// QueryNestedFamily returns the members of a query family with one level of nested keys.
+// E.g. filter[name][like]=x results in {"name": {"like": "x"}}. Members without nested key are stored with an empty key.
+func QueryNestedFamily(req *http.Request, familyName string) (map[string]map[string]string, bool) {
+	dict := make(map[string]map[string]string)
+	family, exist := queryFamily(req.URL.Query(), familyName)
+	if !exist {
+		return dict, false
+	}
+	for k, v := range req.URL.Query() {
+		if !strings.HasPrefix(k, familyName+"[") {
+			continue
+		}
+		member, rest, ok := strings.Cut(k[len(familyName)+1:], "]")
+		if !ok || member == "" {
+			continue
+		}
+		if _, known := family[member]; !known {
+			continue
+		}
+		nestedKey := ""
+		if strings.HasPrefix(rest, "[") && strings.HasSuffix(rest, "]") {
+			nestedKey = rest[1 : len(rest)-1]
+		} else if rest != "" {
+			continue
+		}
+		if dict[member] == nil {
+			dict[member] = make(map[string]string)
+		}
+		dict[member][nestedKey] = v[0]
+	}
+	return dict, true
+}
+
 func QueryFamilyMember(req *http.Request, familyName, memberName string) (string, bool) {
 	if qm, ok := QueryFamily(req, familyName); ok {
 		return qm[memberName], true
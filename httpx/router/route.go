@@ -3,7 +3,10 @@ package router
 import (
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+
+	"github.com/vloryan/go-libs/httpx"
 )
 
 type HandleRouteFunc func(method, path string, handler http.HandlerFunc)
@@ -15,6 +18,8 @@ type RouteElement interface {
 	POST(path string, handler http.HandlerFunc)
 	DELETE(path string, handler http.HandlerFunc)
 	PATCH(path string, handler http.HandlerFunc)
+	// Use adds middlewares wrapping the handlers registered afterward on the route and its sub routes.
+	Use(middlewares ...httpx.Middleware)
 }
 
 func NewRoute(path string, handler HandleRouteFunc) RouteElement {
@@ -43,35 +48,49 @@ func (r *RootRoute) Path() string {
 }
 
 type Route struct {
-	root *RootRoute
-	path string
+	root        *RootRoute
+	path        string
+	middlewares []httpx.Middleware
 }
 
 func (e *Route) SubRoute(path string) RouteElement {
 	return &Route{
-		root: e.root,
-		path: joinPath(e.path, path),
+		root:        e.root,
+		path:        joinPath(e.path, path),
+		middlewares: slices.Clone(e.middlewares),
 	}
 }
 
+func (e *Route) Use(middlewares ...httpx.Middleware) {
+	e.middlewares = append(e.middlewares, middlewares...)
+}
+
 func (e *Route) Path() string {
 	return e.path
 }
 
 func (e *Route) GET(path string, handler http.HandlerFunc) {
-	e.root.handleRouteFunc(http.MethodGet, joinPath(e.path, path), handler)
+	e.root.handleRouteFunc(http.MethodGet, joinPath(e.path, path), e.wrap(handler))
 }
 
 func (e *Route) POST(path string, handler http.HandlerFunc) {
-	e.root.handleRouteFunc(http.MethodPost, joinPath(e.path, path), handler)
+	e.root.handleRouteFunc(http.MethodPost, joinPath(e.path, path), e.wrap(handler))
 }
 
 func (e *Route) DELETE(path string, handler http.HandlerFunc) {
-	e.root.handleRouteFunc(http.MethodDelete, joinPath(e.path, path), handler)
+	e.root.handleRouteFunc(http.MethodDelete, joinPath(e.path, path), e.wrap(handler))
 }
 
 func (e *Route) PATCH(path string, handler http.HandlerFunc) {
-	e.root.handleRouteFunc(http.MethodPatch, joinPath(e.path, path), handler)
+	e.root.handleRouteFunc(http.MethodPatch, joinPath(e.path, path), e.wrap(handler))
+}
+
+// wrap wraps handler by the middlewares of the route.
+func (e *Route) wrap(handler http.HandlerFunc) http.HandlerFunc {
+	if len(e.middlewares) == 0 {
+		return handler
+	}
+	return httpx.Chain(handler, e.middlewares...).ServeHTTP
 }
 
 func joinPath(base string, elem ...string) string {
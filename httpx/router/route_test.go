@@ -2,9 +2,11 @@ package router
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/vloryan/go-libs/httpx"
 )
 
 type DummyRouter struct {
@@ -70,3 +72,42 @@ func TestRouting(t *testing.T) {
 		})
 	}
 }
+
+func TestRoute_Use(t *testing.T) {
+	var got []string
+	record := func(name string) httpx.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				got = append(got, name)
+				next.ServeHTTP(w, req)
+			})
+		}
+	}
+	router := new(DummyRouter)
+	route := NewRoute("/v1", router.handle)
+	route.Use(record("route"))
+	sub := route.SubRoute("sub")
+	sub.Use(record("sub"))
+	route.Use(record("late"))
+
+	tests := []struct {
+		name     string
+		register func(handler http.HandlerFunc)
+		want     []string
+	}{
+		{name: "route", register: func(h http.HandlerFunc) { route.GET("a", h) }, want: []string{"route", "late", "handler"}},
+		{name: "sub route", register: func(h http.HandlerFunc) { sub.GET("b", h) }, want: []string{"route", "sub", "handler"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			tt.register(func(http.ResponseWriter, *http.Request) {
+				got = append(got, "handler")
+			})
+			router.Handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Use() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -2,23 +2,26 @@ package httpx
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/vloryan/go-libs/stringx"
 )
 
 type Server struct {
 	http.Server
 	onStartUp      func()
 	middlewareFunc func(req *http.Request) *http.Request
+	middlewares    []Middleware
+	accessLogger   AccessLogger
 	Router         http.Handler
 	status         Status
+
+	handlerOnce sync.Once
+	handler     http.Handler
 }
 type Status int
 
@@ -42,11 +45,25 @@ func NewServer(router http.Handler) *Server {
 	return s
 }
 
+// WithMiddleware sets a function modifying requests before they pass the middlewares added by Use.
 func (s *Server) WithMiddleware(middlewareFunc func(req *http.Request) *http.Request) *Server {
 	s.middlewareFunc = middlewareFunc
 	return s
 }
 
+// WithAccessLogger sets the logger of answered requests, DefaultAccessLogger if it is nil.
+func (s *Server) WithAccessLogger(accessLogger AccessLogger) *Server {
+	s.accessLogger = accessLogger
+	return s
+}
+
+// Use adds middlewares wrapping the router, they run in the order they are added. The middlewares are chained
+// once when the first request is served, they must be added before.
+func (s *Server) Use(middlewares ...Middleware) *Server {
+	s.middlewares = append(s.middlewares, middlewares...)
+	return s
+}
+
 func (s *Server) WithOnStartUp(onStartUp func()) *Server {
 	s.onStartUp = onStartUp
 	return s
@@ -104,37 +121,54 @@ func (s *Server) Stop() {
 func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	start := time.Now().UTC()
 	sw := &StatusAwareResponseWriter{ResponseWriter: w}
-	defer s.logResponse(sw, req, start)
+	// the request as seen by the router, e.g. with the remote address set by RealIP
+	var served atomic.Pointer[http.Request]
+	served.Store(req)
+	defer func() {
+		entry := newAccessLogEntry(sw, served.Load(), start)
+		p := recover()
+		if p != nil {
+			// the panic is passed on to net/http, which aborts the response
+			entry.Status = http.StatusInternalServerError
+		}
+		s.logAccess(entry)
+		if p != nil {
+			panic(p)
+		}
+	}()
 	if s.middlewareFunc != nil {
 		req = s.middlewareFunc(req)
 	}
-	if s.Router == nil {
-		w.WriteHeader(http.StatusNotFound)
-		return
-	}
-	s.Router.ServeHTTP(sw, req)
+	req = req.WithContext(context.WithValue(req.Context(), servedRequestKey{}, &served))
+	s.handlerOnce.Do(func() {
+		s.handler = s.chain()
+	})
+	s.handler.ServeHTTP(sw, req)
 }
 
-func (s *Server) logResponse(writer *StatusAwareResponseWriter, req *http.Request, start time.Time) {
-	var statusText string
-	if writer.Status() >= http.StatusOK && writer.Status() < http.StatusMultipleChoices {
-		statusText = stringx.FormatColored(stringx.ConsoleColorBgGreen, strconv.Itoa(writer.Status()))
-	} else if writer.Status() >= http.StatusMultipleChoices && writer.Status() < http.StatusBadRequest {
-		statusText = stringx.FormatColored(stringx.ConsoleColorBgGray, strconv.Itoa(writer.Status()))
-	} else if writer.Status() >= http.StatusBadRequest && writer.Status() < http.StatusInternalServerError {
-		statusText = stringx.FormatColored(stringx.ConsoleColorBgYellow, strconv.Itoa(writer.Status()))
-	} else {
-		statusText = stringx.FormatColored(stringx.ConsoleColorBgRed, strconv.Itoa(writer.Status()))
+type servedRequestKey struct{}
+
+// chain wraps the router by the middlewares and captures the request passed to the router for the access log.
+func (s *Server) chain() http.Handler {
+	var handler http.Handler = s.Router
+	if handler == nil {
+		handler = http.NotFoundHandler()
+	}
+	capture := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if served, ok := req.Context().Value(servedRequestKey{}).(*atomic.Pointer[http.Request]); ok {
+				served.Store(req)
+			}
+			next.ServeHTTP(w, req)
+		})
 	}
-	duration := time.Since(start)
-	path := req.URL.Path
-	if req.URL.RawQuery != "" {
-		path += "?" + req.URL.RawQuery
+	return Chain(handler, append(s.middlewares[:len(s.middlewares):len(s.middlewares)], capture)...)
+}
+
+func (s *Server) logAccess(entry AccessLogEntry) {
+	accessLogger := s.accessLogger
+	if accessLogger == nil {
+		accessLogger = defaultAccessLogger()
 	}
-	msg := fmt.Sprintf("%s %s %s %s",
-		statusText,
-		stringx.FormatColoredRight(stringx.ConsoleColorReset, duration.String(), 15),
-		stringx.FormatColoredCenter(stringx.ConsoleColorBgGray, req.Method, 5),
-		path)
-	log.Print(msg)
+	accessLogger.LogAccess(entry)
 }
@@ -4,9 +4,11 @@ import (
 	"net/http"
 )
 
+// StatusAwareResponseWriter tracks the status and the number of body bytes of a response.
 type StatusAwareResponseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	bytes      int64
 }
 
 func (w *StatusAwareResponseWriter) Header() http.Header {
@@ -14,7 +16,12 @@ func (w *StatusAwareResponseWriter) Header() http.Header {
 }
 
 func (w *StatusAwareResponseWriter) Write(b []byte) (int, error) {
-	return w.ResponseWriter.Write(b)
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
 }
 
 func (w *StatusAwareResponseWriter) WriteHeader(statusCode int) {
@@ -22,10 +29,21 @@ func (w *StatusAwareResponseWriter) WriteHeader(statusCode int) {
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Status returns the status of the response, 0 if nothing has been written yet.
 func (w *StatusAwareResponseWriter) Status() int {
 	return w.statusCode
 }
 
+// BytesWritten returns the number of body bytes written.
+func (w *StatusAwareResponseWriter) BytesWritten() int64 {
+	return w.bytes
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (w *StatusAwareResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 type InMemResponseWriter struct {
 	header     http.Header
 	StatusCode int
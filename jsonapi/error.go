@@ -1,6 +1,7 @@
 package jsonapi
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 )
@@ -12,8 +13,56 @@ type Error struct {
 	Code   string         `json:"code,omitempty"`
 	Title  string         `json:"title,omitempty"`
 	Detail string         `json:"detail,omitempty"`
-	Source string         `json:"source,omitempty"`
-	Meta   MetaData       `json:"meta,omitempty"`
+	// Source is rendered as plain string, SourceRef takes precedence.
+	Source string `json:"-"`
+	// SourceRef references the source of the error as object, e.g. the query parameter.
+	SourceRef *ErrorSource `json:"-"`
+	Meta      MetaData     `json:"meta,omitempty"`
+}
+
+// ErrorSource references the primary source of an error.
+type ErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Header    string `json:"header,omitempty"`
+}
+
+// errorJSON is the JSON representation of Error.
+type errorJSON struct {
+	plainError
+	Source any `json:"source,omitempty"`
+}
+
+type plainError Error
+
+func (e Error) MarshalJSON() ([]byte, error) {
+	ej := errorJSON{plainError: plainError(e)}
+	if e.SourceRef != nil {
+		ej.Source = e.SourceRef
+	} else if e.Source != "" {
+		ej.Source = e.Source
+	}
+	return json.Marshal(ej)
+}
+
+func (e *Error) UnmarshalJSON(data []byte) error {
+	var ej struct {
+		*plainError
+		Source json.RawMessage `json:"source,omitempty"`
+	}
+	ej.plainError = (*plainError)(e)
+	if err := json.Unmarshal(data, &ej); err != nil {
+		return err
+	}
+	e.Source, e.SourceRef = "", nil
+	if len(ej.Source) == 0 || string(ej.Source) == "null" {
+		return nil
+	}
+	if ej.Source[0] == '"' {
+		return json.Unmarshal(ej.Source, &e.Source)
+	}
+	e.SourceRef = &ErrorSource{}
+	return json.Unmarshal(ej.Source, e.SourceRef)
 }
 
 func (e *Error) Error() string {
@@ -30,3 +79,10 @@ func NewError(status int, title string, detail error) *Error {
 	}
 	return e
 }
+
+// NewParameterError creates an error caused by the query parameter with the given name.
+func NewParameterError(status int, title string, parameter string, detail error) *Error {
+	e := NewError(status, title, detail)
+	e.SourceRef = &ErrorSource{Parameter: parameter}
+	return e
+}
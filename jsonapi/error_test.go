@@ -0,0 +1,46 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestError_JSON(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *Error
+		want string
+	}{{
+		name: "source object",
+		err:  NewParameterError(400, "invalid filter", "filter[age]", nil),
+		want: `{"status":"400","title":"invalid filter","source":{"parameter":"filter[age]"}}`,
+	}, {
+		name: "source string",
+		err:  &Error{Status: "400", Source: "age"},
+		want: `{"status":"400","source":"age"}`,
+	}, {
+		name: "no source",
+		err:  &Error{Status: "500"},
+		want: `{"status":"500"}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.err)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, string(got)); diff != "" {
+				t.Errorf("Marshal() mismatch (-want +got):\n%s", diff)
+			}
+			var unmarshaled Error
+			if err := json.Unmarshal(got, &unmarshaled); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.err, &unmarshaled); diff != "" {
+				t.Errorf("Unmarshal() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
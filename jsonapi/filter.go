@@ -0,0 +1,147 @@
+package jsonapi
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vloryan/go-libs/httpx"
+	"github.com/vloryan/go-libs/sqlx/filter"
+)
+
+// FilterFields is the allow-list of filterable fields of a resource type. It maps the field names accepted
+// in filter query parameters to the field paths used in the resulting criteria.
+type FilterFields map[string]string
+
+// NewFilterFields creates FilterFields for names which are used unchanged as field paths.
+func NewFilterFields(names ...string) FilterFields {
+	fields := make(FilterFields, len(names))
+	for _, name := range names {
+		fields[name] = name
+	}
+	return fields
+}
+
+type filterOpFunc func(f *filter.FieldFilter, value string) (filter.Criteria, error)
+
+var filterOps = map[string]filterOpFunc{
+	"eq": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		return f.Eq(value), nil
+	},
+	"ne": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		return f.Neq(value), nil
+	},
+	"gt": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		return f.Gt(value), nil
+	},
+	"gte": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		return f.GtEq(value), nil
+	},
+	"lt": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		return f.Lt(value), nil
+	},
+	"lte": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		return f.LtEq(value), nil
+	},
+	"like": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		return f.Like(value), nil
+	},
+	"ilike": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		return f.ToLowerCase().Like(strings.ToLower(value)), nil
+	},
+	"in": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		return f.In(splitFilterValues(value)...), nil
+	},
+	"nin": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		return f.In(splitFilterValues(value)...).Not(), nil
+	},
+	"contains": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		return f.Contains(splitFilterValues(value)...), nil
+	},
+	"between": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		values := splitFilterValues(value)
+		if len(values) != 2 {
+			return nil, errors.New("between requires two comma separated values")
+		}
+		return f.Between(values[0], values[1]), nil
+	},
+	"exists": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		exists, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("exists requires a boolean value")
+		}
+		if exists {
+			return f.Exists(), nil
+		}
+		return f.NotExists(), nil
+	},
+	"null": func(f *filter.FieldFilter, value string) (filter.Criteria, error) {
+		isNil, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("null requires a boolean value")
+		}
+		if isNil {
+			return f.IsNil(), nil
+		}
+		return f.IsNotNil(), nil
+	},
+}
+
+// ExtractFilter parses the filter query parameters of req into criteria. Parameters are of the form
+// filter[field]=value or filter[field][op]=value where op is one of eq, ne, gt, gte, lt, lte, like, ilike,
+// in, nin, contains, between, exists or null. Values of in, nin, contains and between are comma separated.
+// Fields not contained in fields and unknown operators result in a 400 error.
+func ExtractFilter(req *http.Request, fields FilterFields) (filter.Criteria, *Error) {
+	criteria := filter.New()
+	members, exist := httpx.QueryNestedFamily(req, "filter")
+	if !exist {
+		return criteria, nil
+	}
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		ops := members[name]
+		path, ok := fields[name]
+		if !ok {
+			param := "filter[" + name + "]"
+			return nil, NewParameterError(http.StatusBadRequest, "invalid filter", param, errors.New("field '"+name+"' is not filterable"))
+		}
+		opNames := make([]string, 0, len(ops))
+		for op := range ops {
+			opNames = append(opNames, op)
+		}
+		slices.Sort(opNames)
+		for _, op := range opNames {
+			param := "filter[" + name + "]"
+			if op != "" {
+				param += "[" + op + "]"
+			}
+			opFunc, ok := filterOps[op]
+			if op == "" {
+				opFunc = filterOps["eq"]
+			} else if !ok {
+				return nil, NewParameterError(http.StatusBadRequest, "invalid filter", param, errors.New("unknown filter operator '"+op+"'"))
+			}
+			c, err := opFunc(filter.Field(path), ops[op])
+			if err != nil {
+				return nil, NewParameterError(http.StatusBadRequest, "invalid filter", param, err)
+			}
+			criteria = criteria.And(c)
+		}
+	}
+	return criteria, nil
+}
+
+func splitFilterValues(value string) []any {
+	parts := strings.Split(value, ",")
+	values := make([]any, len(parts))
+	for i, part := range parts {
+		values[i] = part
+	}
+	return values
+}
@@ -0,0 +1,79 @@
+package jsonapi
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/vloryan/go-libs/sqlx/filter"
+)
+
+func TestExtractFilter(t *testing.T) {
+	fields := FilterFields{"name": "name", "age": "age", "status": "status", "city": "address.city"}
+	tests := []struct {
+		name    string
+		query   string
+		want    filter.Criteria
+		wantErr *Error
+	}{{
+		name:  "no filter",
+		query: "sort=name",
+		want:  filter.New(),
+	}, {
+		name:  "eq without op",
+		query: "filter[name]=Hans",
+		want:  filter.Field("name").Eq("Hans"),
+	}, {
+		name:  "mapped field path",
+		query: "filter[city][eq]=Berlin",
+		want:  filter.Field("address.city").Eq("Berlin"),
+	}, {
+		name:  "like",
+		query: "filter[name][like]=" + url.QueryEscape("foo%"),
+		want:  filter.Field("name").Like("foo%"),
+	}, {
+		name:  "in",
+		query: "filter[status][in]=a,b",
+		want:  filter.Field("status").In("a", "b"),
+	}, {
+		name:  "multiple ops",
+		query: "filter[age][gte]=18&filter[age][lt]=67&filter[name][ne]=Hans",
+		want:  filter.Field("age").GtEq("18").And(filter.Field("age").Lt("67")).And(filter.Field("name").Neq("Hans")),
+	}, {
+		name:  "between",
+		query: "filter[age][between]=18,67",
+		want:  filter.Field("age").Between("18", "67"),
+	}, {
+		name:  "null",
+		query: "filter[city][null]=false",
+		want:  filter.Field("address.city").IsNotNil(),
+	}, {
+		name:    "unknown field",
+		query:   "filter[password]=secret",
+		wantErr: &Error{Status: "400", Title: "invalid filter", Detail: "field 'password' is not filterable", SourceRef: &ErrorSource{Parameter: "filter[password]"}},
+	}, {
+		name:    "unknown op",
+		query:   "filter[age][approx]=18",
+		wantErr: &Error{Status: "400", Title: "invalid filter", Detail: "unknown filter operator 'approx'", SourceRef: &ErrorSource{Parameter: "filter[age][approx]"}},
+	}, {
+		name:    "invalid value",
+		query:   "filter[age][between]=18",
+		wantErr: &Error{Status: "400", Title: "invalid filter", Detail: "between requires two comma separated values", SourceRef: &ErrorSource{Parameter: "filter[age][between]"}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "http://localhost/people?"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, gotErr := ExtractFilter(req, fields)
+			if diff := cmp.Diff(tt.wantErr, gotErr); diff != "" {
+				t.Fatalf("ExtractFilter() error mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ExtractFilter() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
 	"reflect"
 	"slices"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/vloryan/go-libs/httpx"
+	"github.com/vloryan/go-libs/sqlx/pagination"
 )
 
 type (
@@ -142,6 +144,7 @@ func (h *GenericHandler[T]) NewDoc(req *http.Request, data *DocumentData[T]) *Do
 	}
 
 	h.applyMetadata(doc, data)
+	h.applyLinks(req, doc, data)
 
 	return doc
 }
@@ -259,7 +262,10 @@ func (h *GenericHandler[T]) applyMetadata(doc *Document, data *DocumentData[T])
 	for k, v := range data.MetaData {
 		doc.Meta[k] = v
 	}
-	if data.Page != nil {
+	if data.Page.IsCursor() {
+		doc.Meta["page[size]"] = data.Page.Limit
+		doc.Meta["page[sort]"] = strings.Join(data.Page.Sort, ",")
+	} else if data.Page != nil {
 		doc.Meta["page[limit]"] = data.Page.Limit
 		doc.Meta["page[offset]"] = data.Page.Offset
 		doc.Meta["page[sort]"] = strings.Join(data.Page.Sort, ",")
@@ -267,6 +273,62 @@ func (h *GenericHandler[T]) applyMetadata(doc *Document, data *DocumentData[T])
 	}
 }
 
+// applyLinks sets the pagination links of doc. Links keep all query parameters of req except those of the page.
+func (h *GenericHandler[T]) applyLinks(req *http.Request, doc *Document, data *DocumentData[T]) {
+	page := data.Page
+	if page == nil || (!page.IsCursor() && page.Limit <= 0) {
+		return
+	}
+	if doc.Links == nil {
+		doc.Links = make(map[string]any)
+	}
+	doc.Links["prev"] = nil
+	doc.Links["next"] = nil
+	if page.IsCursor() {
+		if page.Cursor.Prev != "" {
+			doc.Links["prev"] = pageLink(req, map[string]string{"page[before]": page.Cursor.Prev}, "page[after]")
+		}
+		if page.Cursor.Next != "" {
+			doc.Links["next"] = pageLink(req, map[string]string{"page[after]": page.Cursor.Next}, "page[before]")
+		}
+		return
+	}
+	offsetLink := func(p *pagination.Page) string {
+		return pageLink(req, map[string]string{
+			"page[offset]": strconv.Itoa(p.Offset),
+			"page[limit]":  strconv.Itoa(p.Limit),
+		})
+	}
+	doc.Links["first"] = offsetLink(pagination.NewPage(0, page.Limit))
+	doc.Links["last"] = nil
+	if last := page.Last(); last != nil {
+		doc.Links["last"] = offsetLink(last)
+	}
+	if prev := page.Prev(); prev != nil {
+		doc.Links["prev"] = offsetLink(prev)
+	}
+	if next := page.Next(); next != nil {
+		doc.Links["next"] = offsetLink(next)
+	}
+}
+
+// pageLink returns the URL of req with the query parameters params set and the parameters named by remove deleted.
+func pageLink(req *http.Request, params map[string]string, remove ...string) string {
+	u, err := url.Parse(httpx.FullURL(req))
+	if err != nil {
+		return ""
+	}
+	query := u.Query()
+	for _, name := range remove {
+		query.Del(name)
+	}
+	for k, v := range params {
+		query.Set(k, v)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
 func findRelationships(relationships map[string][]*ResourceIdentifierObject, name string) ([]*ResourceIdentifierObject, string) {
 	currentRelationships := relationships
 	var nextRelationships map[string][]*ResourceIdentifierObject
@@ -320,3 +320,81 @@ func TestGenericHandler_applyMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestGenericHandler_applyLinks(t *testing.T) {
+	type testCase struct {
+		name string
+		url  string
+		page *pagination.Page
+		want map[string]any
+	}
+	tests := []testCase{
+		{
+			name: "without page",
+			url:  "http://localhost:8080/items",
+		}, {
+			name: "limitless page",
+			url:  "http://localhost:8080/items?sort=name",
+			page: pagination.SortBy("name"),
+		}, {
+			name: "first offset page",
+			url:  "http://localhost:8080/items?sort=name&page[limit]=10",
+			page: &pagination.Page{Offset: 0, Limit: 10, TotalCount: 25},
+			want: map[string]any{
+				"first": "http://localhost:8080/items?page%5Blimit%5D=10&page%5Boffset%5D=0&sort=name",
+				"last":  "http://localhost:8080/items?page%5Blimit%5D=10&page%5Boffset%5D=20&sort=name",
+				"prev":  nil,
+				"next":  "http://localhost:8080/items?page%5Blimit%5D=10&page%5Boffset%5D=10&sort=name",
+			},
+		}, {
+			name: "last offset page",
+			url:  "http://localhost:8080/items?page[offset]=20&page[limit]=10",
+			page: &pagination.Page{Offset: 20, Limit: 10, TotalCount: 25},
+			want: map[string]any{
+				"first": "http://localhost:8080/items?page%5Blimit%5D=10&page%5Boffset%5D=0",
+				"last":  "http://localhost:8080/items?page%5Blimit%5D=10&page%5Boffset%5D=20",
+				"prev":  "http://localhost:8080/items?page%5Blimit%5D=10&page%5Boffset%5D=10",
+				"next":  nil,
+			},
+		}, {
+			name: "offset page of unknown total",
+			url:  "http://localhost:8080/items?page[offset]=10&page[limit]=10",
+			page: &pagination.Page{Offset: 10, Limit: 10, RowCount: 10},
+			want: map[string]any{
+				"first": "http://localhost:8080/items?page%5Blimit%5D=10&page%5Boffset%5D=0",
+				"last":  nil,
+				"prev":  "http://localhost:8080/items?page%5Blimit%5D=10&page%5Boffset%5D=0",
+				"next":  "http://localhost:8080/items?page%5Blimit%5D=10&page%5Boffset%5D=20",
+			},
+		}, {
+			name: "cursor first page",
+			url:  "http://localhost:8080/items?page[size]=2&filter[name]=a",
+			page: &pagination.Page{Limit: 2, Cursor: &pagination.Cursor{Next: "n1"}},
+			want: map[string]any{
+				"prev": nil,
+				"next": "http://localhost:8080/items?filter%5Bname%5D=a&page%5Bafter%5D=n1&page%5Bsize%5D=2",
+			},
+		}, {
+			name: "cursor middle page",
+			url:  "http://localhost:8080/items?page[size]=2&page[after]=n0",
+			page: &pagination.Page{Limit: 2, Cursor: &pagination.Cursor{After: "n0", Prev: "p1", Next: "n1"}},
+			want: map[string]any{
+				"prev": "http://localhost:8080/items?page%5Bbefore%5D=p1&page%5Bsize%5D=2",
+				"next": "http://localhost:8080/items?page%5Bafter%5D=n1&page%5Bsize%5D=2",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := GenericHandler[*Item]{}
+			doc := NewDocument()
+			req := NewRequest(t, http.MethodGet, tt.url, nil, nil)
+			req.Host = "localhost:8080"
+			h.applyLinks(req, doc, &DocumentData[*Item]{Page: tt.page})
+
+			if diff := cmp.Diff(tt.want, doc.Links); diff != "" {
+				t.Errorf("applyLinks() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -10,13 +10,31 @@ import (
 
 var DefaultPageLimit = 25
 
+// ExtractPagination parses the page and sort query parameters of req. Pages are positioned by page[offset] and
+// page[limit] or, following the cursor pagination profile, by page[after], page[before] and page[size].
 func ExtractPagination(req *http.Request) *pagination.Page {
 	var sorts []string
 	queryParamSort := httpx.Query(req, "sort")
 	if queryParamSort != "" {
 		sorts = strings.Split(queryParamSort, ",")
 	}
+	if isCursorPagination(req) {
+		after, _ := httpx.QueryFamilyMember(req, "page", "after")
+		before, _ := httpx.QueryFamilyMember(req, "page", "before")
+		size := httpx.QueryFamilyMemberInt(req, "page", "size", DefaultPageLimit)
+		return pagination.NewCursorPage(size, after, before, sorts...)
+	}
 	offset := httpx.QueryFamilyMemberInt(req, "page", "offset", 0)
 	limit := httpx.QueryFamilyMemberInt(req, "page", "limit", DefaultPageLimit)
 	return pagination.NewPage(offset, limit, sorts...)
 }
+
+func isCursorPagination(req *http.Request) bool {
+	page := httpx.QueryMap(req, "page")
+	for _, member := range []string{"after", "before", "size"} {
+		if _, ok := page[member]; ok {
+			return true
+		}
+	}
+	return false
+}
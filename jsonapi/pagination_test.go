@@ -0,0 +1,44 @@
+package jsonapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/vloryan/go-libs/sqlx/pagination"
+)
+
+func TestExtractPagination(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  *pagination.Page
+	}{{
+		name:  "default",
+		query: "",
+		want:  pagination.NewPage(0, DefaultPageLimit),
+	}, {
+		name:  "offset",
+		query: "page[offset]=10&page[limit]=5&sort=-name,id",
+		want:  pagination.NewPage(10, 5, "-name", "id"),
+	}, {
+		name:  "cursor after",
+		query: "page[after]=abc&page[size]=5&sort=id",
+		want:  pagination.NewCursorPage(5, "abc", "", "id"),
+	}, {
+		name:  "cursor before with default size",
+		query: "page[before]=abc",
+		want:  pagination.NewCursorPage(DefaultPageLimit, "", "abc"),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "http://localhost/items?"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, ExtractPagination(req)); diff != "" {
+				t.Errorf("ExtractPagination() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
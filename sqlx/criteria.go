@@ -0,0 +1,288 @@
+package sqlx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/vloryan/go-libs/sqlx/filter"
+)
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// CriteriaCompiler renders filter.Criteria into SQL conditions with named parameters,
+// ready to be passed to Select or Exec.
+type CriteriaCompiler struct {
+	Dialect Dialect
+	// ParamPrefix is prepended to the generated parameter names. Defaults to "p".
+	ParamPrefix string
+
+	args map[string]any
+}
+
+// CompileCriteria renders c into a condition usable in a WHERE clause and the named args it references.
+// An empty criteria results in an empty condition.
+func CompileCriteria(c filter.Criteria, dialect Dialect) (string, map[string]any, error) {
+	compiler := &CriteriaCompiler{Dialect: dialect}
+	return compiler.Compile(c)
+}
+
+func (c *CriteriaCompiler) Compile(criteria filter.Criteria) (string, map[string]any, error) {
+	c.args = make(map[string]any)
+	if isEmptyCriteria(criteria) {
+		return "", c.args, nil
+	}
+	cond, err := c.compile(criteria)
+	if err != nil {
+		return "", nil, err
+	}
+	return cond, c.args, nil
+}
+
+func isEmptyCriteria(criteria filter.Criteria) bool {
+	if criteria == nil {
+		return true
+	}
+	_, ok := criteria.(*filter.EmptyCriteria)
+	return ok
+}
+
+func (c *CriteriaCompiler) compile(criteria filter.Criteria) (string, error) {
+	switch cr := criteria.(type) {
+	case nil, *filter.EmptyCriteria:
+		return "1 = 1", nil
+	case *filter.NotCriteria:
+		cond, err := c.compile(cr.C)
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + cond + ")", nil
+	case *filter.BinaryCriteria:
+		return c.compileBinary(cr)
+	case *filter.UnaryCriteria:
+		return c.compileUnary(cr)
+	default:
+		return "", fmt.Errorf("unsupported criteria type %T", criteria)
+	}
+}
+
+func (c *CriteriaCompiler) compileBinary(cr *filter.BinaryCriteria) (string, error) {
+	var op string
+	switch cr.OpType {
+	case filter.OpTypeAnd:
+		op = " AND "
+	case filter.OpTypeOr:
+		op = " OR "
+	default:
+		return "", errors.New("unsupported binary op type " + strconv.Itoa(cr.OpType))
+	}
+	c1, err := c.compile(cr.C1)
+	if err != nil {
+		return "", err
+	}
+	c2, err := c.compile(cr.C2)
+	if err != nil {
+		return "", err
+	}
+	return "(" + c1 + op + c2 + ")", nil
+}
+
+func (c *CriteriaCompiler) compileUnary(cr *filter.UnaryCriteria) (string, error) {
+	if !identifierRegex.MatchString(cr.FieldPath) {
+		return "", errors.New("invalid field path '" + cr.FieldPath + "'")
+	}
+	switch cr.OpType {
+	case filter.ExistsOp:
+		return c.compileExists(cr)
+	case filter.ContainsOp:
+		return c.compileContains(cr)
+	}
+	expr, err := c.fieldExpr(cr)
+	if err != nil {
+		return "", err
+	}
+	switch cr.OpType {
+	case filter.EqOp:
+		if cr.Value == nil {
+			return expr + " IS NULL", nil
+		}
+		return expr + " = " + c.bind(cr.Value), nil
+	case filter.GtOp:
+		return expr + " > " + c.bind(cr.Value), nil
+	case filter.GtEqOp:
+		return expr + " >= " + c.bind(cr.Value), nil
+	case filter.LtOp:
+		return expr + " < " + c.bind(cr.Value), nil
+	case filter.LtEqOp:
+		return expr + " <= " + c.bind(cr.Value), nil
+	case filter.LikeOp:
+		return expr + " LIKE " + c.bind(cr.Value), nil
+	case filter.InOp:
+		values, ok := cr.Value.([]any)
+		if !ok {
+			return "", errors.New("in requires a slice of values for field '" + cr.FieldPath + "'")
+		}
+		if len(values) == 0 {
+			return "1 = 0", nil
+		}
+		params := make([]string, len(values))
+		for i, v := range values {
+			params[i] = c.bind(v)
+		}
+		return expr + " IN (" + strings.Join(params, ", ") + ")", nil
+	case filter.BetweenOp:
+		values, ok := cr.Value.([]any)
+		if !ok || len(values) != 2 {
+			return "", errors.New("between requires a low and a high value for field '" + cr.FieldPath + "'")
+		}
+		return expr + " BETWEEN " + c.bind(values[0]) + " AND " + c.bind(values[1]), nil
+	case filter.FunctionOp:
+		if cr.Function == "" {
+			return "", errors.New("function op requires a function for field '" + cr.FieldPath + "'")
+		}
+		return expr, nil
+	default:
+		return "", errors.New("unsupported op type " + strconv.Itoa(cr.OpType))
+	}
+}
+
+// fieldExpr renders the field of cr with its function applied as scalar value.
+func (c *CriteriaCompiler) fieldExpr(cr *filter.UnaryCriteria) (string, error) {
+	switch cr.Function {
+	case "":
+		return cr.FieldPath, nil
+	case filter.ToLowerFunc:
+		return "LOWER(" + cr.FieldPath + ")", nil
+	case filter.JSONExtractFunc:
+		path, err := c.jsonPathArg(cr)
+		if err != nil {
+			return "", err
+		}
+		switch c.Dialect {
+		case Postgres:
+			return "(CAST(" + cr.FieldPath + " AS jsonb) #>> CAST(" + path + " AS text[]))", nil
+		case MySQL:
+			return "JSON_UNQUOTE(JSON_EXTRACT(" + cr.FieldPath + ", " + path + "))", nil
+		case SQLServer:
+			return "JSON_VALUE(" + cr.FieldPath + ", " + path + ")", nil
+		default:
+			return "json_extract(" + cr.FieldPath + ", " + path + ")", nil
+		}
+	default:
+		return "", errors.New("unsupported function '" + cr.Function + "'")
+	}
+}
+
+// jsonPathArg binds the json path argument of cr in the notation of the dialect.
+func (c *CriteriaCompiler) jsonPathArg(cr *filter.UnaryCriteria) (string, error) {
+	if len(cr.Args) != 1 {
+		return "", errors.New(filter.JSONExtractFunc + " requires exactly one path argument for field '" + cr.FieldPath + "'")
+	}
+	if c.Dialect != Postgres {
+		return c.bind(filter.NormalizeJSONPath(cr.Args[0])), nil
+	}
+	elems, err := filter.SplitJSONPath(cr.Args[0])
+	if err != nil {
+		return "", err
+	}
+	for i, elem := range elems {
+		if strings.ContainsAny(elem, `,{}" `) {
+			elems[i] = strconv.Quote(elem)
+		}
+	}
+	return c.bind("{" + strings.Join(elems, ",") + "}"), nil
+}
+
+func (c *CriteriaCompiler) compileExists(cr *filter.UnaryCriteria) (string, error) {
+	if cr.Function != filter.JSONExtractFunc {
+		expr, err := c.fieldExpr(cr)
+		if err != nil {
+			return "", err
+		}
+		return expr + " IS NOT NULL", nil
+	}
+	path, err := c.jsonPathArg(cr)
+	if err != nil {
+		return "", err
+	}
+	switch c.Dialect {
+	case Postgres:
+		return "(CAST(" + cr.FieldPath + " AS jsonb) #> CAST(" + path + " AS text[])) IS NOT NULL", nil
+	case MySQL:
+		return "JSON_CONTAINS_PATH(" + cr.FieldPath + ", 'one', " + path + ") = 1", nil
+	case SQLServer:
+		return "JSON_PATH_EXISTS(" + cr.FieldPath + ", " + path + ") = 1", nil
+	default:
+		return "json_type(" + cr.FieldPath + ", " + path + ") IS NOT NULL", nil
+	}
+}
+
+// compileContains renders a condition which is true if the JSON array of the field contains all elements.
+func (c *CriteriaCompiler) compileContains(cr *filter.UnaryCriteria) (string, error) {
+	elems, ok := cr.Value.([]any)
+	if !ok {
+		return "", errors.New("contains requires a slice of elements for field '" + cr.FieldPath + "'")
+	}
+	if len(elems) == 0 {
+		return "1 = 1", nil
+	}
+	if cr.Function != "" && cr.Function != filter.JSONExtractFunc {
+		return "", errors.New("function '" + cr.Function + "' is not supported by contains")
+	}
+	var path string
+	if cr.Function == filter.JSONExtractFunc {
+		var err error
+		if path, err = c.jsonPathArg(cr); err != nil {
+			return "", err
+		}
+	}
+	switch c.Dialect {
+	case Postgres, MySQL:
+		b, err := json.Marshal(elems)
+		if err != nil {
+			return "", err
+		}
+		if c.Dialect == MySQL {
+			if path != "" {
+				return "JSON_CONTAINS(" + cr.FieldPath + ", " + c.bind(string(b)) + ", " + path + ") = 1", nil
+			}
+			return "JSON_CONTAINS(" + cr.FieldPath + ", " + c.bind(string(b)) + ") = 1", nil
+		}
+		expr := "CAST(" + cr.FieldPath + " AS jsonb)"
+		if path != "" {
+			expr = "(" + expr + " #> CAST(" + path + " AS text[]))"
+		}
+		return expr + " @> CAST(" + c.bind(string(b)) + " AS jsonb)", nil
+	default:
+		fn := "json_each("
+		if c.Dialect == SQLServer {
+			fn = "OPENJSON("
+		}
+		source := fn + cr.FieldPath + ")"
+		if path != "" {
+			source = fn + cr.FieldPath + ", " + path + ")"
+		}
+		conds := make([]string, len(elems))
+		for i, elem := range elems {
+			conds[i] = "EXISTS (SELECT 1 FROM " + source + " WHERE value = " + c.bind(elem) + ")"
+		}
+		if len(conds) == 1 {
+			return conds[0], nil
+		}
+		return "(" + strings.Join(conds, " AND ") + ")", nil
+	}
+}
+
+// bind registers v as named arg and returns its parameter marker.
+func (c *CriteriaCompiler) bind(v any) string {
+	prefix := c.ParamPrefix
+	if prefix == "" {
+		prefix = "p"
+	}
+	name := prefix + strconv.Itoa(len(c.args)+1)
+	c.args[name] = v
+	return ":" + name
+}
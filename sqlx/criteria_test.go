@@ -0,0 +1,215 @@
+package sqlx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/vloryan/go-libs/sqlx/filter"
+)
+
+func TestCompileCriteria(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria filter.Criteria
+		dialect  Dialect
+		want     string
+		wantArgs map[string]any
+		wantErr  bool
+	}{{
+		name:     "empty",
+		criteria: filter.New(),
+		want:     "",
+		wantArgs: map[string]any{},
+	}, {
+		name:     "eq",
+		criteria: filter.Field("name").Eq("Hans"),
+		want:     "name = :p1",
+		wantArgs: map[string]any{"p1": "Hans"},
+	}, {
+		name:     "is nil",
+		criteria: filter.Field("name").IsNotNil(),
+		want:     "NOT (name IS NULL)",
+		wantArgs: map[string]any{},
+	}, {
+		name:     "and or",
+		criteria: filter.Field("age").GtEq(18).And(filter.Field("age").Lt(67)).Or(filter.Object("person").Field("retired").IsTrue()),
+		want:     "((age >= :p1 AND age < :p2) OR person.retired = :p3)",
+		wantArgs: map[string]any{"p1": 18, "p2": 67, "p3": true},
+	}, {
+		name:     "in",
+		criteria: filter.Field("id").In(1, 2, 3),
+		want:     "id IN (:p1, :p2, :p3)",
+		wantArgs: map[string]any{"p1": 1, "p2": 2, "p3": 3},
+	}, {
+		name:     "in without values",
+		criteria: filter.Field("id").In(),
+		want:     "1 = 0",
+		wantArgs: map[string]any{},
+	}, {
+		name:     "between",
+		criteria: filter.Field("age").Between(18, 67),
+		want:     "age BETWEEN :p1 AND :p2",
+		wantArgs: map[string]any{"p1": 18, "p2": 67},
+	}, {
+		name:     "like to lower",
+		criteria: filter.Field("name").ToLowerCase().Like("ha%"),
+		want:     "LOWER(name) LIKE :p1",
+		wantArgs: map[string]any{"p1": "ha%"},
+	}, {
+		name:     "json extract sqlite",
+		criteria: filter.Field("doc").JSONExtract("a.b").Eq("x"),
+		want:     "json_extract(doc, :p1) = :p2",
+		wantArgs: map[string]any{"p1": "$.a.b", "p2": "x"},
+	}, {
+		name:     "json extract postgres",
+		criteria: filter.Field("doc").JSONExtract("$.a[0]").Eq("x"),
+		dialect:  Postgres,
+		want:     "(CAST(doc AS jsonb) #>> CAST(:p1 AS text[])) = :p2",
+		wantArgs: map[string]any{"p1": "{a,0}", "p2": "x"},
+	}, {
+		name:     "json extract mysql",
+		criteria: filter.Field("doc").JSONExtract("a").Eq("x"),
+		dialect:  MySQL,
+		want:     "JSON_UNQUOTE(JSON_EXTRACT(doc, :p1)) = :p2",
+		wantArgs: map[string]any{"p1": "$.a", "p2": "x"},
+	}, {
+		name:     "exists",
+		criteria: filter.Field("name").Exists(),
+		want:     "name IS NOT NULL",
+		wantArgs: map[string]any{},
+	}, {
+		name:     "exists json sqlite",
+		criteria: filter.Field("doc").JSONExtract("a").Exists(),
+		want:     "json_type(doc, :p1) IS NOT NULL",
+		wantArgs: map[string]any{"p1": "$.a"},
+	}, {
+		name:     "exists json postgres",
+		criteria: filter.Field("doc").JSONExtract("a").Exists(),
+		dialect:  Postgres,
+		want:     "(CAST(doc AS jsonb) #> CAST(:p1 AS text[])) IS NOT NULL",
+		wantArgs: map[string]any{"p1": "{a}"},
+	}, {
+		name:     "exists json mysql",
+		criteria: filter.Field("doc").JSONExtract("a").Exists(),
+		dialect:  MySQL,
+		want:     "JSON_CONTAINS_PATH(doc, 'one', :p1) = 1",
+		wantArgs: map[string]any{"p1": "$.a"},
+	}, {
+		name:     "contains sqlite",
+		criteria: filter.Field("tags").Contains("a", "b"),
+		want:     "(EXISTS (SELECT 1 FROM json_each(tags) WHERE value = :p1) AND EXISTS (SELECT 1 FROM json_each(tags) WHERE value = :p2))",
+		wantArgs: map[string]any{"p1": "a", "p2": "b"},
+	}, {
+		name:     "contains postgres",
+		criteria: filter.Field("tags").Contains("a", "b"),
+		dialect:  Postgres,
+		want:     "CAST(tags AS jsonb) @> CAST(:p1 AS jsonb)",
+		wantArgs: map[string]any{"p1": `["a","b"]`},
+	}, {
+		name:     "contains mysql json path",
+		criteria: filter.Field("doc").JSONExtract("tags").Contains(1),
+		dialect:  MySQL,
+		want:     "JSON_CONTAINS(doc, :p2, :p1) = 1",
+		wantArgs: map[string]any{"p1": "$.tags", "p2": "[1]"},
+	}, {
+		name:     "json extract sqlserver",
+		criteria: filter.Field("doc").JSONExtract("a").Eq("x"),
+		dialect:  SQLServer,
+		want:     "JSON_VALUE(doc, :p1) = :p2",
+		wantArgs: map[string]any{"p1": "$.a", "p2": "x"},
+	}, {
+		name:     "contains sqlserver",
+		criteria: filter.Field("tags").Contains("a"),
+		dialect:  SQLServer,
+		want:     "EXISTS (SELECT 1 FROM OPENJSON(tags) WHERE value = :p1)",
+		wantArgs: map[string]any{"p1": "a"},
+	}, {
+		name:     "invalid field path",
+		criteria: filter.Field("name; DROP TABLE x").Eq(1),
+		wantErr:  true,
+	}, {
+		name:     "unknown function",
+		criteria: &filter.UnaryCriteria{OpType: filter.EqOp, FieldPath: "name", Function: "upper", Value: "A"},
+		wantErr:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotArgs, err := CompileCriteria(tt.criteria, tt.dialect)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CompileCriteria() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("CompileCriteria() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantArgs, gotArgs); diff != "" {
+				t.Errorf("CompileCriteria() args mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompileCriteria_SQLite(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria filter.Criteria
+		want     []string
+	}{{
+		name:     "like",
+		criteria: filter.Field("name").Like("Ma%"),
+		want:     []string{"Maxima"},
+	}, {
+		name:     "not in",
+		criteria: filter.Field("name").In("Maxima", "Hans").Not(),
+		want:     []string{"Ludger"},
+	}, {
+		name:     "json extract",
+		criteria: filter.Field("doc").JSONExtract("$.age").Gt(40),
+		want:     []string{"Hans", "Ludger"},
+	}, {
+		name:     "json exists",
+		criteria: filter.Field("doc").JSONExtract("$.tags").Exists(),
+		want:     []string{"Maxima", "Ludger"},
+	}, {
+		name:     "json contains",
+		criteria: filter.Field("doc").JSONExtract("$.tags").Contains("b", "c"),
+		want:     []string{"Ludger"},
+	}}
+	db, err := Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE people (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, doc TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range []map[string]any{
+		{"name": "Maxima", "doc": `{"age": 32, "tags": ["a", "b"]}`},
+		{"name": "Hans", "doc": `{"age": 45}`},
+		{"name": "Ludger", "doc": `{"age": 51, "tags": ["b", "c"]}`},
+	} {
+		if _, err := db.Exec("INSERT INTO people(name, doc) VALUES(:name, :doc)", row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, args, err := CompileCriteria(tt.criteria, SQLite)
+			if err != nil {
+				t.Fatalf("CompileCriteria() error = %v", err)
+			}
+			var got []*testStruct
+			if err := db.Select(&got, "SELECT id, name FROM people WHERE "+cond+" ORDER BY id", args); err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			gotNames := make([]string, len(got))
+			for i, p := range got {
+				gotNames[i] = p.Name
+			}
+			if diff := cmp.Diff(tt.want, gotNames); diff != "" {
+				t.Errorf("Select() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
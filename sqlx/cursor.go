@@ -0,0 +1,182 @@
+package sqlx
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/vloryan/go-libs/reflectx"
+	"github.com/vloryan/go-libs/sqlx/pagination"
+)
+
+type keysetColumn struct {
+	column string
+	index  []int
+	desc   bool
+}
+
+// selectCursorPage selects the page of query following page.Cursor.After and/or preceding page.Cursor.Before.
+// The query is wrapped in a sub query which is restricted by the keyset of the sort columns, so the sorts must
+// identify rows uniquely. Named args of the query must be passed as map. page.Cursor.Next and page.Cursor.Prev
+// are set to the cursors of the adjacent pages.
+func selectCursorPage(ctx context.Context, q NamedQuerier, dest any, query string, page *pagination.Page, args ...any) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		return errors.New("dest is no pointer to slice")
+	}
+	elemType := reflectx.ElemTypeOf(dest, true)
+	if elemType.Kind() != reflect.Struct {
+		return errors.New("dest is no slice of structs")
+	}
+	if len(page.Sort) == 0 {
+		return errors.New("cursor pagination requires a sort")
+	}
+	columns := make([]keysetColumn, len(page.Sort))
+	for i, sort := range page.Sort {
+		sort = strings.TrimSpace(sort)
+		desc := strings.HasPrefix(sort, "-")
+		column, index, ok := sortField(elemType, strings.TrimPrefix(sort, "-"))
+		if !ok {
+			return errors.New("invalid sort '" + sort + "'")
+		}
+		columns[i] = keysetColumn{column: column, index: index, desc: desc}
+	}
+
+	cursor := page.Cursor
+	backward := cursor.Before != "" && cursor.After == ""
+	keysetArgs := make(map[string]any)
+	var conds []string
+	if cursor.After != "" {
+		cond, err := keysetCondition(columns, cursor.After, false, "after", keysetArgs)
+		if err != nil {
+			return err
+		}
+		conds = append(conds, cond)
+	}
+	if cursor.Before != "" {
+		cond, err := keysetCondition(columns, cursor.Before, true, "before", keysetArgs)
+		if err != nil {
+			return err
+		}
+		conds = append(conds, cond)
+	}
+	namedArgs, err := mergeNamedArgs(args, keysetArgs)
+	if err != nil {
+		return err
+	}
+
+	pagedQuery := "SELECT * FROM (" + trimQuery(query) + ") AS cursor_page"
+	if len(conds) > 0 {
+		pagedQuery += " WHERE " + strings.Join(conds, " AND ")
+	}
+	terms := make([]string, len(columns))
+	for i, c := range columns {
+		if c.desc != backward {
+			terms[i] = c.column + " DESC"
+		} else {
+			terms[i] = c.column + " ASC"
+		}
+	}
+	pagedQuery += " ORDER BY " + strings.Join(terms, ", ")
+	if page.Limit > 0 {
+		pagedQuery += " " + q.Dialect().limitClause(page.Limit+1, 0)
+	}
+
+	rows := reflect.New(destValue.Elem().Type())
+	if err := q.SelectContext(ctx, rows.Interface(), pagedQuery, namedArgs...); err != nil {
+		return err
+	}
+	result := rows.Elem()
+	hasMore := page.Limit > 0 && result.Len() > page.Limit
+	if hasMore {
+		result = result.Slice(0, page.Limit)
+	}
+	if backward {
+		swap := reflect.Swapper(result.Interface())
+		for i, j := 0, result.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+	destValue.Elem().Set(result)
+
+	cursor.Next, cursor.Prev = "", ""
+	if result.Len() == 0 {
+		return nil
+	}
+	// there are rows after the page if more rows were found or the page ends before a known row
+	if (hasMore && !backward) || cursor.Before != "" {
+		if cursor.Next, err = rowCursor(result.Index(result.Len()-1), columns); err != nil {
+			return err
+		}
+	}
+	if (hasMore && backward) || cursor.After != "" {
+		if cursor.Prev, err = rowCursor(result.Index(0), columns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keysetCondition renders the condition selecting the rows after (or before if reverse is set) the row identified
+// by the encoded sort key values of cursor, e.g. (a > :after0) OR (a = :after0 AND b > :after1).
+func keysetCondition(columns []keysetColumn, cursor string, reverse bool, prefix string, args map[string]any) (string, error) {
+	values, err := pagination.DecodeCursor(cursor)
+	if err != nil {
+		return "", err
+	}
+	if len(values) != len(columns) {
+		return "", pagination.ErrInvalidCursor
+	}
+	names := make([]string, len(columns))
+	for i, v := range values {
+		names[i] = prefix + strconv.Itoa(i)
+		args[names[i]] = v
+	}
+	alternatives := make([]string, len(columns))
+	for i, c := range columns {
+		terms := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			terms = append(terms, columns[j].column+" = :"+names[j])
+		}
+		op := " > "
+		if c.desc != reverse {
+			op = " < "
+		}
+		terms = append(terms, c.column+op+":"+names[i])
+		alternatives[i] = "(" + strings.Join(terms, " AND ") + ")"
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", nil
+}
+
+func rowCursor(row reflect.Value, columns []keysetColumn) (string, error) {
+	row = reflectx.DeRefValue(row)
+	values := make([]any, len(columns))
+	for i, c := range columns {
+		f, err := row.FieldByIndexErr(c.index)
+		if err != nil {
+			return "", err
+		}
+		values[i] = f.Interface()
+	}
+	return pagination.EncodeCursor(values...)
+}
+
+// mergeNamedArgs combines the named args of a query with additional args.
+func mergeNamedArgs(args []any, additional map[string]any) ([]any, error) {
+	if len(additional) == 0 {
+		return args, nil
+	}
+	if len(args) == 0 || args[0] == nil {
+		return []any{additional}, nil
+	}
+	m, ok := args[0].(map[string]any)
+	if !ok {
+		return nil, errors.New("named args of type '" + reflect.TypeOf(args[0]).String() + "' can not be combined, use a map")
+	}
+	merged := maps.Clone(m)
+	maps.Copy(merged, additional)
+	return []any{merged}, nil
+}
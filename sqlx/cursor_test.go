@@ -0,0 +1,56 @@
+package sqlx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/vloryan/go-libs/sqlx/pagination"
+)
+
+func TestSelectPage_Cursor(t *testing.T) {
+	db := prepareDB(t)
+	for _, name := range []string{"Maxima", "Ludger", "Hans", "Anna", "Hans"} {
+		if _, err := db.Exec("INSERT INTO test_table(name) VALUES(:name);", map[string]any{"name": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	selectNames := func(t *testing.T, page *pagination.Page) []string {
+		var got []*testStruct
+		if err := db.SelectPage(&got, "SELECT * FROM test_table WHERE name <> :name", page, map[string]any{"name": "Nobody"}); err != nil {
+			t.Fatalf("SelectPage() error = %v", err)
+		}
+		names := make([]string, len(got))
+		for i, p := range got {
+			names[i] = p.Name
+		}
+		return names
+	}
+
+	var pages [][]string
+	page := pagination.NewCursorPage(2, "", "", "name", "-id")
+	for {
+		pages = append(pages, selectNames(t, page))
+		if page.Cursor.Next == "" {
+			break
+		}
+		page = pagination.NewCursorPage(2, page.Cursor.Next, "", "name", "-id")
+	}
+	want := [][]string{{"Anna", "Hans"}, {"Hans", "Ludger"}, {"Maxima"}}
+	if diff := cmp.Diff(want, pages); diff != "" {
+		t.Fatalf("forward pages mismatch (-want +got):\n%s", diff)
+	}
+
+	pages = nil
+	for page.Cursor.Prev != "" {
+		page = pagination.NewCursorPage(2, "", page.Cursor.Prev, "name", "-id")
+		pages = append(pages, selectNames(t, page))
+	}
+	want = [][]string{{"Hans", "Ludger"}, {"Anna", "Hans"}}
+	if diff := cmp.Diff(want, pages); diff != "" {
+		t.Fatalf("backward pages mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := db.SelectPage(&[]*testStruct{}, "SELECT * FROM test_table", pagination.NewCursorPage(2, "invalid", "", "id")); err == nil {
+		t.Fatalf("SelectPage() expected error for invalid cursor")
+	}
+}
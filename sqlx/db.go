@@ -1,7 +1,12 @@
 package sqlx
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/vloryan/go-libs/sqlx/pagination"
 )
 
 type RowMapper interface {
@@ -10,36 +15,197 @@ type RowMapper interface {
 
 type DB struct {
 	DB *sql.DB
+	// RetryPolicy controls the retries of InTx, see DefaultRetryPolicy.
+	RetryPolicy RetryPolicy
+	session
+
+	pool        []func(db *sql.DB)
+	replicas    *replicaSet
+	replicaDSNs []string
+}
+
+// Option configures a DB.
+type Option func(db *DB)
+
+// WithDialect sets the dialect used to compile queries, overriding the one inferred from the driver name.
+func WithDialect(dialect Dialect) Option {
+	return func(db *DB) {
+		db.dialect = dialect
+	}
+}
+
+// WithStrictColumns makes scanning rows into structs fail on columns without matching field.
+func WithStrictColumns() Option {
+	return func(db *DB) {
+		db.strict = true
+	}
+}
+
+// WithHooks adds hooks observing the statements of the DB and its transactions.
+func WithHooks(hooks ...Hook) Option {
+	return func(db *DB) {
+		db.hooks = append(db.hooks, hooks...)
+	}
+}
+
+// WithStatementCache caches up to size compiled queries and prepared statements, which are reused by the DB and
+// its transactions. Queries without args, e.g. scripts of multiple statements, are not prepared. The statements
+// are closed by Close. A size of 0 or less disables the cache.
+func WithStatementCache(size int) Option {
+	return func(db *DB) {
+		if size <= 0 {
+			db.stmts = nil
+			return
+		}
+		db.stmts = newStmtCache(db.DB, size)
+	}
+}
+
+// NewDB wraps an opened database.
+func NewDB(db *sql.DB, opts ...Option) *DB {
+	_db := newDB(db, opts)
+	_db.start()
+	return _db
+}
+
+func newDB(db *sql.DB, opts []Option) *DB {
+	_db := &DB{DB: db, RetryPolicy: DefaultRetryPolicy, replicas: &replicaSet{}}
+	for _, opt := range opts {
+		opt(_db)
+	}
+	return _db
+}
+
+// start configures the pools and starts the health checks of the replicas.
+func (db *DB) start() {
+	for _, configure := range db.pool {
+		configure(db.DB)
+		for _, r := range db.replicas.dbs() {
+			configure(r)
+		}
+	}
+	db.replicas.start()
+}
+
+// reader returns the database reads are routed to, a replica unless ctx forces the primary.
+func (db *DB) reader(ctx context.Context) sqlQueryer {
+	if isPrimaryForced(ctx) {
+		return db.DB
+	}
+	if r := db.replicas.pick(); r != nil {
+		return r
+	}
+	return db.DB
 }
 
 func (db *DB) Select(dest any, query string, args ...any) error {
-	return Select(db.DB, dest, query, args...)
+	return db.SelectContext(context.Background(), dest, query, args...)
+}
+
+func (db *DB) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return selectContext(ctx, db.reader(ctx), db.session, dest, query, args...)
+}
+
+func (db *DB) Get(dest any, query string, args ...any) error {
+	return db.GetContext(context.Background(), dest, query, args...)
+}
+
+func (db *DB) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return getContext(ctx, db.reader(ctx), db.session, dest, query, args...)
+}
+
+// QueryContext queries rows binding the named parameters of query.
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return queryContext(ctx, db.reader(ctx), db.session, query, args...)
 }
 
 func (db *DB) Exec(query string, args ...any) (sql.Result, error) {
-	return Exec(db.DB, query, args...)
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return execContext(ctx, db.DB, db.session, query, args...)
+}
+
+func (db *DB) SelectPage(dest any, query string, page *pagination.Page, args ...any) error {
+	return SelectPage(db, dest, query, page, args...)
 }
 
+func (db *DB) SelectPageContext(ctx context.Context, dest any, query string, page *pagination.Page, args ...any) error {
+	return SelectPageContext(ctx, db, dest, query, page, args...)
+}
+
+// Close closes the cached statements, the replicas and the primary.
 func (db *DB) Close() error {
-	return db.DB.Close()
+	var errs []error
+	if db.stmts != nil {
+		errs = append(errs, db.stmts.close())
+	}
+	errs = append(errs, db.replicas.close(), db.DB.Close())
+	return errors.Join(errs...)
 }
 
 func (db *DB) Ping() error {
 	return db.DB.Ping()
 }
 
+func (db *DB) PingContext(ctx context.Context) error {
+	return db.DB.PingContext(ctx)
+}
+
 func (db *DB) Begin() (*Transaction, error) {
-	sqlTx, err := db.DB.Begin()
+	return db.BeginTx(context.Background(), nil)
+}
+
+// BeginTx starts a transaction. The transaction is rolled back if ctx is canceled before it is committed.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Transaction, error) {
+	sqlTx, err := db.DB.BeginTx(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
-	return &Transaction{tx: sqlTx}, nil
+	return &Transaction{tx: sqlTx, session: db.session}, nil
 }
 
-func Open(driverName, dataSourceName string) (*DB, error) {
+// InTx runs fn in a transaction which is committed if fn succeeds and rolled back if fn returns an error or
+// panics. Serialization failures are retried according to db.RetryPolicy.
+func (db *DB) InTx(ctx context.Context, fn TxFunc) error {
+	return db.InTxWithOptions(ctx, nil, fn)
+}
+
+func (db *DB) InTxWithOptions(ctx context.Context, opts *sql.TxOptions, fn TxFunc) error {
+	policy := db.RetryPolicy
+	for attempt := 1; ; attempt++ {
+		tx, err := db.BeginTx(ctx, opts)
+		if err == nil {
+			err = runInTx(tx, fn)
+		}
+		if err == nil || attempt >= policy.attempts() || !policy.retryable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(policy.backoff(attempt)):
+		}
+	}
+}
+
+// Open opens a database. The dialect is inferred from driverName and can be set by WithDialect for other drivers.
+// Replicas are opened with the same driver, see WithReplicaDSNs.
+func Open(driverName, dataSourceName string, opts ...Option) (*DB, error) {
 	_db, err := sql.Open(driverName, dataSourceName)
 	if err != nil {
 		return nil, err
 	}
-	return &DB{DB: _db}, err
+	dialect, _ := DialectOf(driverName)
+	db := newDB(_db, append([]Option{WithDialect(dialect)}, opts...))
+	for _, dsn := range db.replicaDSNs {
+		r, err := sql.Open(driverName, dsn)
+		if err != nil {
+			return nil, errors.Join(err, db.Close())
+		}
+		db.replicas.add(r)
+	}
+	db.start()
+	return db, nil
 }
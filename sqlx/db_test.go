@@ -1,10 +1,15 @@
 package sqlx
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/go-cmp/cmp"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/vloryan/go-libs/sqlx/pagination"
 )
 
 func TestOpenPingClose(t *testing.T) {
@@ -118,3 +123,212 @@ func createTestTable(t *testing.T, db *DB) {
 		t.Fatal(err)
 	}
 }
+
+func Test_DB_Context(t *testing.T) {
+	db := prepareDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := db.ExecContext(ctx, "INSERT INTO test_table(name) VALUES(:name);", map[string]any{"name": "Hans"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ExecContext() error = %v, want %v", err, context.Canceled)
+	}
+	var actual []*testStruct
+	if err := db.SelectContext(ctx, &actual, "SELECT * FROM test_table;"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("SelectContext() error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := db.BeginTx(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("BeginTx() error = %v, want %v", err, context.Canceled)
+	}
+
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("BeginTx() error = %v", err)
+	}
+	defer func(tx *Transaction) {
+		_ = tx.Rollback()
+	}(tx)
+	if err := tx.SelectContext(context.Background(), &actual, "SELECT * FROM test_table;"); err != nil {
+		t.Fatalf("SelectContext() error = %v", err)
+	}
+}
+
+func TestOpen_Dialect(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want Dialect
+	}{
+		{name: "inferred", want: SQLite},
+		{name: "explicit", opts: []Option{WithDialect(Postgres)}, want: Postgres},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := Open("sqlite3", ":memory:", tt.opts...)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer func(db *DB) {
+				_ = db.Close()
+			}(db)
+			if got := db.Dialect(); got != tt.want {
+				t.Errorf("Dialect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_DB_Dialect(t *testing.T) {
+	tests := []struct {
+		name        string
+		dialect     Dialect
+		query       func(db *DB) error
+		setupExpect func(m sqlmock.Sqlmock)
+	}{{
+		name:    "postgres",
+		dialect: Postgres,
+		query: func(db *DB) error {
+			var got []*testStruct
+			return db.Select(&got, "SELECT * FROM tab WHERE name = :name AND data->>'x' = :x::text", map[string]any{"name": "Hans", "x": "y"})
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("SELECT * FROM tab WHERE name = $1 AND data->>'x' = $2::text").
+				WithArgs("Hans", "y").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+		},
+	}, {
+		name:    "sqlserver",
+		dialect: SQLServer,
+		query: func(db *DB) error {
+			_, err := db.Exec("UPDATE tab SET name = :name WHERE id = :id", map[string]any{"name": "Hans", "id": 1})
+			return err
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("UPDATE tab SET name = @p1 WHERE id = @p2").
+				WithArgs("Hans", 1).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		},
+	}, {
+		name:    "sqlserver page",
+		dialect: SQLServer,
+		query: func(db *DB) error {
+			var got []*testStruct
+			return db.SelectPage(&got, "SELECT * FROM tab", pagination.NewPage(2, 2))
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("SELECT * FROM tab ORDER BY (SELECT NULL) OFFSET 2 ROWS FETCH NEXT 2 ROWS ONLY").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+			m.ExpectQuery("SELECT COUNT(*) AS count FROM (SELECT * FROM tab) AS page_query").
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		},
+	}, {
+		name:    "sqlserver savepoint",
+		dialect: SQLServer,
+		query: func(db *DB) error {
+			return db.InTx(context.Background(), func(tx *Transaction) error {
+				return tx.InTx(context.Background(), func(tx *Transaction) error {
+					return nil
+				})
+			})
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectExec("SAVE TRANSACTION sqlx_sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+			m.ExpectCommit()
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.setupExpect(mock)
+			if err := tt.query(NewDB(sqlDB, WithDialect(tt.dialect))); err != nil {
+				t.Fatalf("query error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func Test_DB_Get(t *testing.T) {
+	db := prepareDB(t)
+	for _, name := range []string{"Maxima", "Ludger"} {
+		if _, err := db.Exec("INSERT INTO test_table(name) VALUES(:name);", map[string]any{"name": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := &testStruct{}
+	if err := db.Get(got, "SELECT * FROM test_table WHERE name = :name", map[string]any{"name": "Ludger"}); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if diff := cmp.Diff(&testStruct{ID: 2, Name: "Ludger"}, got); diff != "" {
+		t.Fatalf("Get() mismatch (-want +got):\n%s", diff)
+	}
+	if err := db.Get(got, "SELECT * FROM test_table WHERE name = :name", map[string]any{"name": "Nobody"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Get() error = %v, want %v", err, sql.ErrNoRows)
+	}
+	var all []*testStruct
+	if err := db.Get(&all, "SELECT * FROM test_table"); err == nil {
+		t.Fatal("Get() expected error for slice")
+	}
+
+	count, err := SelectScalar[int](db, "SELECT COUNT(*) FROM test_table WHERE name <> :name", map[string]any{"name": "Nobody"})
+	if err != nil {
+		t.Fatalf("SelectScalar() error = %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("SelectScalar() = %d, want 2", count)
+	}
+	name, err := SelectScalar[sql.NullString](db, "SELECT NULL")
+	if err != nil {
+		t.Fatalf("SelectScalar() error = %v", err)
+	}
+	if name.Valid {
+		t.Fatalf("SelectScalar() = %v, want NULL", name)
+	}
+	if _, err := SelectScalar[string](db, "SELECT id, name FROM test_table"); err == nil {
+		t.Fatal("SelectScalar() expected error for multiple columns")
+	}
+	if _, err := SelectScalar[string](db, "SELECT name FROM test_table WHERE id = 3"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("SelectScalar() error = %v, want %v", err, sql.ErrNoRows)
+	}
+}
+
+func Test_DB_Select_Rows(t *testing.T) {
+	db := prepareDB(t)
+	for _, name := range []string{"Maxima", "Ludger"} {
+		if _, err := db.Exec("INSERT INTO test_table(name) VALUES(:name);", map[string]any{"name": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var names []string
+	if err := db.Select(&names, "SELECT name FROM test_table ORDER BY id"); err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"Maxima", "Ludger"}, names); diff != "" {
+		t.Errorf("Select() mismatch (-want +got):\n%s", diff)
+	}
+	var ids []*int64
+	if err := db.Select(&ids, "SELECT id FROM test_table ORDER BY id"); err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if len(ids) != 2 || *ids[1] != 2 {
+		t.Errorf("Select() = %v", ids)
+	}
+	var rows []map[string]any
+	if err := db.Select(&rows, "SELECT * FROM test_table ORDER BY id"); err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if diff := cmp.Diff([]map[string]any{{"id": int64(1), "name": "Maxima"}, {"id": int64(2), "name": "Ludger"}}, rows); diff != "" {
+		t.Errorf("Select() mismatch (-want +got):\n%s", diff)
+	}
+	var values []testStruct
+	if err := db.Select(&values, "SELECT * FROM test_table ORDER BY id"); err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if len(values) != 2 || values[1].Name != "Ludger" {
+		t.Errorf("Select() = %v", values)
+	}
+}
@@ -0,0 +1,123 @@
+package sqlx
+
+import "strconv"
+
+// Dialect identifies the SQL flavour of a database. SQLite is the zero value and thus the default.
+type Dialect int
+
+const (
+	SQLite Dialect = iota
+	Postgres
+	MySQL
+	SQLServer
+)
+
+// driverDialects maps the names of common drivers to their dialect.
+var driverDialects = map[string]Dialect{
+	"sqlite":    SQLite,
+	"sqlite3":   SQLite,
+	"postgres":  Postgres,
+	"pgx":       Postgres,
+	"mysql":     MySQL,
+	"sqlserver": SQLServer,
+	"mssql":     SQLServer,
+}
+
+// DialectOf returns the dialect of the driver registered as driverName.
+func DialectOf(driverName string) (Dialect, bool) {
+	d, ok := driverDialects[driverName]
+	return d, ok
+}
+
+func (d Dialect) String() string {
+	switch d {
+	case SQLite:
+		return "sqlite"
+	case Postgres:
+		return "postgres"
+	case MySQL:
+		return "mysql"
+	case SQLServer:
+		return "sqlserver"
+	default:
+		return "unknown"
+	}
+}
+
+// paramMarker returns the marker named parameters are compiled to.
+func (d Dialect) paramMarker() rune {
+	switch d {
+	case Postgres:
+		return DOLLAR
+	case SQLServer:
+		return AT
+	default:
+		return QUESTION
+	}
+}
+
+// backslashEscapes reports whether a backslash escapes the following character of a quoted string.
+func (d Dialect) backslashEscapes() bool {
+	return d == MySQL
+}
+
+// limitClause renders LIMIT and OFFSET. A limit less or equal to 0 means no limit.
+// SQL Server requires the clause to follow an ORDER BY.
+func (d Dialect) limitClause(limit, offset int) string {
+	if d == SQLServer {
+		switch {
+		case limit > 0:
+			return "OFFSET " + strconv.Itoa(max(offset, 0)) + " ROWS FETCH NEXT " + strconv.Itoa(limit) + " ROWS ONLY"
+		case offset > 0:
+			return "OFFSET " + strconv.Itoa(offset) + " ROWS"
+		default:
+			return ""
+		}
+	}
+	switch {
+	case limit > 0 && offset > 0:
+		return "LIMIT " + strconv.Itoa(limit) + " OFFSET " + strconv.Itoa(offset)
+	case limit > 0:
+		return "LIMIT " + strconv.Itoa(limit)
+	case offset > 0:
+		switch d {
+		case Postgres:
+			return "OFFSET " + strconv.Itoa(offset)
+		case MySQL:
+			return "LIMIT 18446744073709551615 OFFSET " + strconv.Itoa(offset)
+		default:
+			return "LIMIT -1 OFFSET " + strconv.Itoa(offset)
+		}
+	default:
+		return ""
+	}
+}
+
+// savepoint renders the statements to create, release and roll back to the savepoint name.
+// SQL Server has no release, an empty statement is returned.
+func (d Dialect) savepoint(name string) (create, release, rollback string) {
+	if d == SQLServer {
+		return "SAVE TRANSACTION " + name, "", "ROLLBACK TRANSACTION " + name
+	}
+	return "SAVEPOINT " + name, "RELEASE SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name
+}
+
+// maxParams returns the maximum number of parameters of a statement.
+func (d Dialect) maxParams() int {
+	switch d {
+	case Postgres, MySQL:
+		return 65535
+	case SQLServer:
+		return 2100
+	default:
+		return 999
+	}
+}
+
+// maxValuesRows returns the maximum number of rows of a VALUES clause, 0 if there is no limit.
+func (d Dialect) maxValuesRows() int {
+	if d == SQLServer {
+		return 1000
+	}
+	return 0
+}
@@ -0,0 +1,510 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vloryan/go-libs/reflectx"
+)
+
+// Match reports whether v satisfies c. v may be a struct, a map with string keys or a pointer to either.
+// Field paths are resolved like reflectx.FindField does, a path element addressing a slice without index
+// matches if any of the slice elements does.
+func Match(c Criteria, v any) (bool, error) {
+	return evaluate(c, reflect.ValueOf(v))
+}
+
+// Filter returns the items which satisfy c.
+func Filter[T any](c Criteria, items []T) ([]T, error) {
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		ok, err := Match(c, item)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+func evaluate(c Criteria, v reflect.Value) (bool, error) {
+	switch cr := c.(type) {
+	case nil, *EmptyCriteria:
+		return true, nil
+	case *NotCriteria:
+		ok, err := evaluate(cr.C, v)
+		return !ok, err
+	case *BinaryCriteria:
+		ok, err := evaluate(cr.C1, v)
+		if err != nil {
+			return false, err
+		}
+		switch cr.OpType {
+		case OpTypeAnd:
+			if !ok {
+				return false, nil
+			}
+		case OpTypeOr:
+			if ok {
+				return true, nil
+			}
+		default:
+			return false, errors.New("unsupported binary op type " + strconv.Itoa(cr.OpType))
+		}
+		return evaluate(cr.C2, v)
+	case *UnaryCriteria:
+		return evaluateUnary(cr, v)
+	default:
+		return false, fmt.Errorf("unsupported criteria type %T", c)
+	}
+}
+
+func evaluateUnary(cr *UnaryCriteria, v reflect.Value) (bool, error) {
+	values, found := resolvePath(v, cr.FieldPath)
+	if cr.Function != "" {
+		var err error
+		if values, found, err = applyFunction(cr, values, found); err != nil {
+			return false, err
+		}
+	}
+	switch cr.OpType {
+	case ExistsOp:
+		return found, nil
+	case EqOp:
+		if cr.Value == nil {
+			if !found {
+				return true, nil
+			}
+			return anyValue(values, func(a any) bool { return a == nil }), nil
+		}
+		return anyValue(values, func(a any) bool { return equal(a, cr.Value) }), nil
+	case GtOp:
+		return anyValue(values, func(a any) bool { c, ok := compare(a, cr.Value); return ok && c > 0 }), nil
+	case GtEqOp:
+		return anyValue(values, func(a any) bool { c, ok := compare(a, cr.Value); return ok && c >= 0 }), nil
+	case LtOp:
+		return anyValue(values, func(a any) bool { c, ok := compare(a, cr.Value); return ok && c < 0 }), nil
+	case LtEqOp:
+		return anyValue(values, func(a any) bool { c, ok := compare(a, cr.Value); return ok && c <= 0 }), nil
+	case LikeOp:
+		pattern, ok := cr.Value.(string)
+		if !ok {
+			return false, errors.New("like requires a string pattern for field '" + cr.FieldPath + "'")
+		}
+		re, err := likeRegexp(pattern)
+		if err != nil {
+			return false, err
+		}
+		return anyValue(values, func(a any) bool {
+			s, ok := a.(string)
+			return ok && re.MatchString(s)
+		}), nil
+	case InOp:
+		in, ok := cr.Value.([]any)
+		if !ok {
+			return false, errors.New("in requires a slice of values for field '" + cr.FieldPath + "'")
+		}
+		return anyValue(values, func(a any) bool {
+			for _, e := range in {
+				if equal(a, e) {
+					return true
+				}
+			}
+			return false
+		}), nil
+	case ContainsOp:
+		elems, ok := cr.Value.([]any)
+		if !ok {
+			return false, errors.New("contains requires a slice of elements for field '" + cr.FieldPath + "'")
+		}
+		return anyValue(values, func(a any) bool { return containsAll(a, elems) }), nil
+	case BetweenOp:
+		bounds, ok := cr.Value.([]any)
+		if !ok || len(bounds) != 2 {
+			return false, errors.New("between requires a low and a high value for field '" + cr.FieldPath + "'")
+		}
+		return anyValue(values, func(a any) bool {
+			low, okLow := compare(a, bounds[0])
+			high, okHigh := compare(a, bounds[1])
+			return okLow && okHigh && low >= 0 && high <= 0
+		}), nil
+	case FunctionOp:
+		if cr.Function == "" {
+			return false, errors.New("function op requires a function for field '" + cr.FieldPath + "'")
+		}
+		return anyValue(values, func(a any) bool { return equal(a, true) }), nil
+	default:
+		return false, errors.New("unsupported op type " + strconv.Itoa(cr.OpType))
+	}
+}
+
+func anyValue(values []any, f func(a any) bool) bool {
+	for _, v := range values {
+		if f(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePath collects the values addressed by path. found is false if no element of the path exists.
+func resolvePath(v reflect.Value, path string) ([]any, bool) {
+	current := []reflect.Value{v}
+	for _, part := range strings.Split(path, ".") {
+		var next []reflect.Value
+		for _, cv := range current {
+			next = append(next, resolvePart(cv, part)...)
+		}
+		if len(next) == 0 {
+			return nil, false
+		}
+		current = next
+	}
+	values := make([]any, 0, len(current))
+	for _, cv := range current {
+		values = append(values, valueInterface(cv))
+	}
+	return values, true
+}
+
+func resolvePart(v reflect.Value, part string) []reflect.Value {
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if !v.CanInterface() {
+			return nil
+		}
+		f := reflectx.FindField(v.Interface(), part)
+		if !f.IsValid() || !f.CanInterface() {
+			return nil
+		}
+		return []reflect.Value{f}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		key, index, hasIndex := splitIndex(part)
+		f := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+		if !f.IsValid() {
+			return nil
+		}
+		if hasIndex {
+			return indexValue(f, index)
+		}
+		return []reflect.Value{f}
+	case reflect.Slice, reflect.Array:
+		var values []reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, resolvePart(v.Index(i), part)...)
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func splitIndex(part string) (string, string, bool) {
+	idx := strings.Index(part, "[")
+	if idx == -1 || !strings.HasSuffix(part, "]") {
+		return part, "", false
+	}
+	return part[:idx], part[idx+1 : len(part)-1], true
+}
+
+func indexValue(v reflect.Value, index string) []reflect.Value {
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		i, err := strconv.Atoi(index)
+		if err != nil || i < 0 || i >= v.Len() {
+			return nil
+		}
+		return []reflect.Value{v.Index(i)}
+	case reflect.Map:
+		f := v.MapIndex(reflect.ValueOf(index))
+		if !f.IsValid() {
+			return nil
+		}
+		return []reflect.Value{f}
+	default:
+		return nil
+	}
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func valueInterface(v reflect.Value) any {
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	return v.Interface()
+}
+
+func applyFunction(cr *UnaryCriteria, values []any, found bool) ([]any, bool, error) {
+	switch cr.Function {
+	case ToLowerFunc:
+		result := make([]any, len(values))
+		for i, v := range values {
+			if s, ok := v.(string); ok {
+				result[i] = strings.ToLower(s)
+			} else {
+				result[i] = v
+			}
+		}
+		return result, found, nil
+	case JSONExtractFunc:
+		if len(cr.Args) != 1 {
+			return nil, false, errors.New(JSONExtractFunc + " requires exactly one path argument for field '" + cr.FieldPath + "'")
+		}
+		elems, err := SplitJSONPath(cr.Args[0])
+		if err != nil {
+			return nil, false, err
+		}
+		var result []any
+		for _, v := range values {
+			extracted, ok, err := jsonExtract(v, elems)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				result = append(result, extracted)
+			}
+		}
+		return result, len(result) > 0, nil
+	default:
+		return nil, false, errors.New("unsupported function '" + cr.Function + "'")
+	}
+}
+
+func jsonExtract(v any, path []string) (any, bool, error) {
+	var doc any
+	switch jv := v.(type) {
+	case nil:
+		return nil, false, nil
+	case string:
+		if err := json.Unmarshal([]byte(jv), &doc); err != nil {
+			return nil, false, err
+		}
+	case []byte:
+		if err := json.Unmarshal(jv, &doc); err != nil {
+			return nil, false, err
+		}
+	case json.RawMessage:
+		if err := json.Unmarshal(jv, &doc); err != nil {
+			return nil, false, err
+		}
+	default:
+		b, err := json.Marshal(jv)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return nil, false, err
+		}
+	}
+	for _, elem := range path {
+		switch d := doc.(type) {
+		case map[string]any:
+			var ok bool
+			if doc, ok = d[elem]; !ok {
+				return nil, false, nil
+			}
+		case []any:
+			i, err := strconv.Atoi(elem)
+			if err != nil || i < 0 || i >= len(d) {
+				return nil, false, nil
+			}
+			doc = d[i]
+		default:
+			return nil, false, nil
+		}
+	}
+	return doc, true, nil
+}
+
+func containsAll(v any, elems []any) bool {
+	rv := indirect(reflect.ValueOf(v))
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		return false
+	}
+	for _, elem := range elems {
+		contained := false
+		for i := 0; i < rv.Len(); i++ {
+			if equal(valueInterface(rv.Index(i)), elem) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return false
+		}
+	}
+	return true
+}
+
+func equal(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if ab, ok := a.(bool); ok {
+		bb, ok := toBool(b)
+		return ok && ab == bb
+	}
+	if c, ok := compare(a, b); ok {
+		return c == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compare returns -1, 0 or +1 depending on whether a is less, equal or greater than b.
+// ok is false if a and b are not comparable.
+func compare(a, b any) (int, bool) {
+	a, b = valueInterface(reflect.ValueOf(a)), valueInterface(reflect.ValueOf(b))
+	if a == nil || b == nil {
+		return 0, false
+	}
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		if !ok {
+			// values from query strings or JSON documents are often strings
+			bs, isString := b.(string)
+			if !isString {
+				return 0, false
+			}
+			var err error
+			if bf, err = strconv.ParseFloat(bs, 64); err != nil {
+				return 0, false
+			}
+		}
+		switch {
+		case af < bf:
+			return -1, true
+		case af > bf:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	if at, ok := a.(time.Time); ok {
+		bt, ok := toTime(b)
+		if !ok {
+			return 0, false
+		}
+		return at.Compare(bt), true
+	}
+	if as, ok := toString(a); ok {
+		bs, ok := toString(b)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(as, bs), true
+	}
+	return 0, false
+}
+
+func toFloat(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int()), true
+	case rv.CanUint():
+		return float64(rv.Uint()), true
+	case rv.CanFloat():
+		return rv.Float(), true
+	case rv.Kind() == reflect.String:
+		if _, isNum := v.(json.Number); !isNum {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(rv.String(), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func toString(v any) (string, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.String {
+		return rv.String(), true
+	}
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String(), true
+	}
+	return "", false
+}
+
+func toTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339, t)
+		return parsed, err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+func toBool(v any) (bool, bool) {
+	switch b := v.(type) {
+	case bool:
+		return b, true
+	case string:
+		parsed, err := strconv.ParseBool(b)
+		return parsed, err == nil
+	default:
+		f, ok := toFloat(v)
+		return f != 0, ok
+	}
+}
+
+// likeRegexp translates a SQL LIKE pattern into a regular expression. '%' matches any sequence of characters
+// and '_' any single character, '\' escapes the next character.
+func likeRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("(?s)^")
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			sb.WriteString(".*")
+		case r == '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
@@ -0,0 +1,134 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type evalAddress struct {
+	City string
+}
+
+type evalPerson struct {
+	Name     string
+	Age      int
+	Active   bool
+	Birthday *time.Time
+	Tags     []string
+	Address  *evalAddress
+	Children []*evalPerson
+	Doc      string
+	Extra    map[string]any
+}
+
+func TestMatch(t *testing.T) {
+	birthday := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	person := &evalPerson{
+		Name:     "Maxima",
+		Age:      34,
+		Active:   true,
+		Birthday: &birthday,
+		Tags:     []string{"a", "b"},
+		Address:  &evalAddress{City: "Berlin"},
+		Children: []*evalPerson{{Name: "Ludger", Age: 4}, {Name: "Hans", Age: 7}},
+		Doc:      `{"size": 42, "labels": ["x", "y"], "nested": {"key": "value"}}`,
+		Extra:    map[string]any{"level": 3, "list": []any{"first", "second"}},
+	}
+	tests := []struct {
+		name     string
+		criteria Criteria
+		want     bool
+		wantErr  bool
+	}{
+		{name: "empty", criteria: New(), want: true},
+		{name: "eq", criteria: Field("name").Eq("Maxima"), want: true},
+		{name: "eq mismatch", criteria: Field("name").Eq("Hans"), want: false},
+		{name: "eq numeric types", criteria: Field("age").Eq(int64(34)), want: true},
+		{name: "eq numeric string", criteria: Field("age").Eq("34"), want: true},
+		{name: "neq", criteria: Field("name").Neq("Hans"), want: true},
+		{name: "is true", criteria: Field("active").IsTrue(), want: true},
+		{name: "is nil", criteria: Field("address").IsNil(), want: false},
+		{name: "is not nil", criteria: Field("address").IsNotNil(), want: true},
+		{name: "gt", criteria: Field("age").Gt(30), want: true},
+		{name: "lt eq", criteria: Field("age").LtEq(33), want: false},
+		{name: "between", criteria: Field("age").Between(30, 34), want: true},
+		{name: "time", criteria: Field("birthday").Lt(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)), want: true},
+		{name: "in", criteria: Field("name").In("Hans", "Maxima"), want: true},
+		{name: "like", criteria: Field("name").Like("Ma%a"), want: true},
+		{name: "like single char", criteria: Field("name").Like("Ma_ima"), want: true},
+		{name: "like case sensitive", criteria: Field("name").Like("ma%"), want: false},
+		{name: "like to lower", criteria: Field("name").ToLowerCase().Like("ma%"), want: true},
+		{name: "contains", criteria: Field("tags").Contains("b", "a"), want: true},
+		{name: "contains missing", criteria: Field("tags").Contains("a", "c"), want: false},
+		{name: "nested object", criteria: Object("address").Field("city").Eq("Berlin"), want: true},
+		{name: "any slice element", criteria: Object("children").Field("name").Eq("Hans"), want: true},
+		{name: "indexed slice element", criteria: Field("children[0].name").Eq("Hans"), want: false},
+		{name: "map", criteria: Object("extra").Field("level").GtEq(3), want: true},
+		{name: "map indexed", criteria: Field("extra.list[1]").Eq("second"), want: true},
+		{name: "exists", criteria: Object("extra").Field("level").Exists(), want: true},
+		{name: "not exists", criteria: Object("extra").Field("missing").NotExists(), want: true},
+		{name: "nil or not exists", criteria: Object("extra").Field("missing").IsNilOrNotExists(), want: true},
+		{name: "json extract", criteria: Field("doc").JSONExtract("$.size").Eq(42), want: true},
+		{name: "json extract nested", criteria: Field("doc").JSONExtract("nested.key").Eq("value"), want: true},
+		{name: "json exists", criteria: Field("doc").JSONExtract("$.nested.other").Exists(), want: false},
+		{name: "json contains", criteria: Field("doc").JSONExtract("$.labels").Contains("y"), want: true},
+		{name: "and", criteria: Field("age").Gt(30).And(Field("name").Eq("Hans")), want: false},
+		{name: "or", criteria: Field("age").Gt(30).Or(Field("name").Eq("Hans")), want: true},
+		{name: "unknown function", criteria: &UnaryCriteria{OpType: EqOp, FieldPath: "name", Function: "upper"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Match(tt.criteria, person)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Match() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	people := []map[string]any{
+		{"name": "Maxima", "age": 34},
+		{"name": "Ludger", "age": 4},
+		{"name": "Hans", "age": 7},
+	}
+	got, err := Filter(Field("age").Lt(10), people)
+	if err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	if diff := cmp.Diff(people[1:], got); diff != "" {
+		t.Errorf("Filter() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSplitJSONPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{path: "$", want: nil},
+		{path: "$.a.b", want: []string{"a", "b"}},
+		{path: "a.b", want: []string{"a", "b"}},
+		{path: "$.a[2].b", want: []string{"a", "2", "b"}},
+		{path: `$."a.b".c`, want: []string{"a.b", "c"}},
+		{path: "$.a[2", wantErr: true},
+		{path: "$..a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := SplitJSONPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitJSONPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("SplitJSONPath() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"errors"
+	"strings"
+)
+
+// SplitJSONPath splits a JSON path as used by JSONExtract into its elements.
+// Paths may start with the root marker '$', keys are separated by '.' and array indexes are written as [n].
+// Keys containing special characters can be double-quoted, e.g. $."a.b"[0].c
+func SplitJSONPath(path string) ([]string, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var elems []string
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			if i < len(path) && path[i] == '"' {
+				end := strings.IndexByte(path[i+1:], '"')
+				if end == -1 {
+					return nil, errors.New("unterminated quoted key in json path '" + path + "'")
+				}
+				elems = append(elems, path[i+1:i+1+end])
+				i += end + 2
+				continue
+			}
+			end := strings.IndexAny(path[i:], ".[")
+			if end == -1 {
+				end = len(path) - i
+			}
+			if end == 0 {
+				return nil, errors.New("empty key in json path '" + path + "'")
+			}
+			elems = append(elems, path[i:i+end])
+			i += end
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end == -1 {
+				return nil, errors.New("unterminated index in json path '" + path + "'")
+			}
+			elems = append(elems, path[i+1:i+end])
+			i += end + 1
+		default:
+			if i == 0 {
+				// relative path without leading '$.'
+				path = "." + path
+				continue
+			}
+			return nil, errors.New("unexpected character '" + string(path[i]) + "' in json path '" + path + "'")
+		}
+	}
+	return elems, nil
+}
+
+// NormalizeJSONPath returns path in its absolute form starting with '$'.
+func NormalizeJSONPath(path string) string {
+	path = strings.TrimSpace(path)
+	if strings.HasPrefix(path, "$") {
+		return path
+	}
+	if strings.HasPrefix(path, "[") {
+		return "$" + path
+	}
+	return "$." + path
+}
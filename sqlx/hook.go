@@ -0,0 +1,123 @@
+package sqlx
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Redacted replaces the values of sensitive args in QueryEvent.
+const Redacted = "[REDACTED]"
+
+// QueryEvent describes a statement sent to the database.
+type QueryEvent struct {
+	// Query is the compiled statement.
+	Query string
+	// Args are the bound args, sensitive values are replaced by Redacted.
+	Args  []any
+	Start time.Time
+	// Duration, RowsAffected and Err are set after the statement has been executed, for Select and Get after the
+	// rows have been scanned.
+	Duration time.Duration
+	// RowsAffected is the number of rows affected by Exec, -1 if unknown.
+	RowsAffected int64
+	Err          error
+}
+
+// Hook observes the statements executed by a DB and its transactions. The context returned by BeforeQuery
+// is used to execute the statement, e.g. to carry a tracing span.
+type Hook interface {
+	BeforeQuery(ctx context.Context, event *QueryEvent) context.Context
+	AfterQuery(ctx context.Context, event *QueryEvent)
+}
+
+type sensitiveArg struct {
+	value any
+}
+
+// Sensitive marks an arg whose value must not be exposed to hooks. Values of struct fields with the "sensitive"
+// tag option, e.g. `db:"password,sensitive"`, are marked implicitly.
+func Sensitive(v any) any {
+	return sensitiveArg{value: v}
+}
+
+// unwrapSensitive returns the args passed to the driver and the args exposed to hooks.
+func unwrapSensitive(args []any) (driverArgs, eventArgs []any) {
+	driverArgs = args
+	for i, arg := range args {
+		sensitive, ok := arg.(sensitiveArg)
+		if !ok {
+			continue
+		}
+		if eventArgs == nil {
+			driverArgs = append([]any{}, args...)
+			eventArgs = append([]any{}, args...)
+		}
+		driverArgs[i] = sensitive.value
+		eventArgs[i] = Redacted
+	}
+	if eventArgs == nil {
+		eventArgs = args
+	}
+	return driverArgs, eventArgs
+}
+
+func (s session) beforeQuery(ctx context.Context, query string, args []any) (context.Context, *QueryEvent) {
+	event := &QueryEvent{Query: query, Args: args, Start: time.Now(), RowsAffected: -1}
+	for _, h := range s.hooks {
+		ctx = h.BeforeQuery(ctx, event)
+	}
+	return ctx, event
+}
+
+func (s session) afterQuery(ctx context.Context, event *QueryEvent, err error) {
+	event.Duration = time.Since(event.Start)
+	event.Err = err
+	for i := len(s.hooks) - 1; i >= 0; i-- {
+		s.hooks[i].AfterQuery(ctx, event)
+	}
+}
+
+// ZerologHook logs statements. Statements taking at least SlowThreshold are logged at warn level,
+// failed ones at error level and all others at Level.
+type ZerologHook struct {
+	Logger zerolog.Logger
+	Level  zerolog.Level
+	// SlowThreshold of 0 disables the detection of slow statements.
+	SlowThreshold time.Duration
+}
+
+// NewZerologHook creates a hook logging statements at debug level.
+func NewZerologHook(logger zerolog.Logger, slowThreshold time.Duration) *ZerologHook {
+	return &ZerologHook{Logger: logger, Level: zerolog.DebugLevel, SlowThreshold: slowThreshold}
+}
+
+func (h *ZerologHook) BeforeQuery(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *ZerologHook) AfterQuery(_ context.Context, event *QueryEvent) {
+	slow := h.SlowThreshold > 0 && event.Duration >= h.SlowThreshold
+	level := h.Level
+	switch {
+	case event.Err != nil:
+		level = zerolog.ErrorLevel
+	case slow:
+		level = zerolog.WarnLevel
+	}
+	e := h.Logger.WithLevel(level).
+		Str("query", event.Query).
+		Interface("args", event.Args).
+		Dur("duration", event.Duration)
+	if event.RowsAffected >= 0 {
+		e = e.Int64("rows_affected", event.RowsAffected)
+	}
+	if slow {
+		e = e.Bool("slow", true)
+	}
+	if event.Err != nil {
+		e = e.Err(event.Err)
+	}
+	e.Msg("sql query")
+}
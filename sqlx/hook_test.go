@@ -0,0 +1,155 @@
+package sqlx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/rs/zerolog"
+)
+
+type recordingHook struct {
+	events []QueryEvent
+}
+
+func (h *recordingHook) BeforeQuery(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *recordingHook) AfterQuery(_ context.Context, event *QueryEvent) {
+	h.events = append(h.events, *event)
+}
+
+type hookUser struct {
+	ID       int64
+	Name     string
+	Password string `db:"password,sensitive"`
+}
+
+func Test_DB_Hooks(t *testing.T) {
+	tests := []struct {
+		name  string
+		query func(db *DB) error
+		want  []QueryEvent
+	}{{
+		name: "exec",
+		query: func(db *DB) error {
+			_, err := db.Exec("INSERT INTO users(name, password) VALUES(:name, :password)", &hookUser{Name: "Hans", Password: "secret"})
+			return err
+		},
+		want: []QueryEvent{{
+			Query:        "INSERT INTO users(name, password) VALUES(?, ?)",
+			Args:         []any{"Hans", Redacted},
+			RowsAffected: 1,
+		}},
+	}, {
+		name: "select",
+		query: func(db *DB) error {
+			var users []hookUser
+			return db.Select(&users, "SELECT * FROM users WHERE password IN (:passwords)", map[string]any{"passwords": Sensitive([]string{"a", "b"})})
+		},
+		want: []QueryEvent{{
+			Query:        "SELECT * FROM users WHERE password IN (?, ?)",
+			Args:         []any{Redacted, Redacted},
+			RowsAffected: -1,
+		}},
+	}, {
+		name: "error",
+		query: func(db *DB) error {
+			_, err := db.Exec("DELETE FROM unknown")
+			return err
+		},
+		want: []QueryEvent{{
+			Query:        "DELETE FROM unknown",
+			RowsAffected: -1,
+			Err:          errors.New("no such table: unknown"),
+		}},
+	}, {
+		name: "scan error",
+		query: func(db *DB) error {
+			var users []hookUser
+			return db.Select(&users, "SELECT 'x' AS id")
+		},
+		want: []QueryEvent{{
+			Query:        "SELECT 'x' AS id",
+			RowsAffected: -1,
+			Err:          errors.New("converting 'x' failed"),
+		}},
+	}, {
+		name: "transaction",
+		query: func(db *DB) error {
+			return db.InTx(context.Background(), func(tx *Transaction) error {
+				_, err := tx.Exec("UPDATE users SET name = :name", map[string]any{"name": "Anna"})
+				return err
+			})
+		},
+		want: []QueryEvent{{
+			Query:        "UPDATE users SET name = ?",
+			Args:         []any{"Anna"},
+			RowsAffected: 0,
+		}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := Open("sqlite3", ":memory:")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, password TEXT NOT NULL)"); err != nil {
+				t.Fatal(err)
+			}
+			hook := &recordingHook{}
+			WithHooks(hook)(db)
+			err = tt.query(db)
+			if (err != nil) != (tt.want[len(tt.want)-1].Err != nil) {
+				t.Fatalf("query error = %v", err)
+			}
+			opts := []cmp.Option{
+				cmpopts.IgnoreFields(QueryEvent{}, "Start", "Duration"),
+				cmp.Comparer(func(a, b error) bool { return (a == nil) == (b == nil) }),
+			}
+			if diff := cmp.Diff(tt.want, hook.events, opts...); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestZerologHook(t *testing.T) {
+	tests := []struct {
+		name  string
+		event QueryEvent
+		want  map[string]any
+	}{{
+		name:  "debug",
+		event: QueryEvent{Query: "SELECT 1", Args: []any{Redacted}, Duration: time.Millisecond, RowsAffected: -1},
+		want:  map[string]any{"level": "debug", "query": "SELECT 1", "args": []any{Redacted}, "duration": 1.0, "message": "sql query"},
+	}, {
+		name:  "slow",
+		event: QueryEvent{Query: "DELETE FROM t", Duration: time.Second, RowsAffected: 2},
+		want:  map[string]any{"level": "warn", "query": "DELETE FROM t", "args": nil, "duration": 1000.0, "rows_affected": 2.0, "slow": true, "message": "sql query"},
+	}, {
+		name:  "error",
+		event: QueryEvent{Query: "DELETE FROM t", Duration: time.Millisecond, RowsAffected: -1, Err: errors.New("failed")},
+		want:  map[string]any{"level": "error", "query": "DELETE FROM t", "args": nil, "duration": 1.0, "error": "failed", "message": "sql query"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			hook := NewZerologHook(zerolog.New(&buf), 100*time.Millisecond)
+			hook.AfterQuery(context.Background(), &tt.event)
+			var got map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("log mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
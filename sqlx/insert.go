@@ -0,0 +1,199 @@
+package sqlx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vloryan/go-libs/reflectx"
+)
+
+// InsertMany inserts rows, a slice of structs, into table using multi row INSERT statements. The columns are
+// derived from the fields of the struct: the db tag or the snake cased field name. Fields tagged with "-" or the
+// "auto" option, e.g. `db:"id,auto"` for generated keys, or the "readonly" option are skipped. The rows are split into batches respecting
+// the parameter limit of the dialect, multiple batches are inserted in a transaction if q supports them.
+// The tenant and audit timestamps of the rows are set as described by Scope. The number of affected rows is returned.
+func InsertMany(q NamedQuerier, table string, rows any) (int64, error) {
+	return InsertManyContext(context.Background(), q, table, rows)
+}
+
+func InsertManyContext(ctx context.Context, q NamedQuerier, table string, rows any) (int64, error) {
+	return insertMany(ctx, q, table, rows, nil)
+}
+
+// Upsert inserts rows like InsertMany. Rows conflicting with existing ones on conflictColumns update the remaining
+// columns instead. MySQL resolves conflicts on any unique key, SQL Server is not supported. If q is scoped by a
+// tenant, conflicting rows of other tenants are left unchanged, which MySQL does not support.
+func Upsert(q NamedQuerier, table string, rows any, conflictColumns ...string) (int64, error) {
+	return UpsertContext(context.Background(), q, table, rows, conflictColumns...)
+}
+
+func UpsertContext(ctx context.Context, q NamedQuerier, table string, rows any, conflictColumns ...string) (int64, error) {
+	if len(conflictColumns) == 0 {
+		return 0, errors.New("upsert requires conflict columns")
+	}
+	return insertMany(ctx, q, table, rows, conflictColumns)
+}
+
+func insertMany(ctx context.Context, q NamedQuerier, table string, rows any, conflictColumns []string) (int64, error) {
+	rowsValue := reflectx.DeRefValue(reflect.ValueOf(rows))
+	if rowsValue.Kind() != reflect.Slice && rowsValue.Kind() != reflect.Array {
+		return 0, errors.New("rows is no slice")
+	}
+	elemType := reflectx.DeRef(rowsValue.Type().Elem())
+	if elemType.Kind() != reflect.Struct {
+		return 0, errors.New("rows is no slice of structs")
+	}
+	if !identifierRegex.MatchString(table) {
+		return 0, errors.New("invalid table '" + table + "'")
+	}
+	columns := insertColumns(elemType)
+	if len(columns) == 0 {
+		return 0, errors.New("no columns to insert for type '" + elemType.String() + "'")
+	}
+	if rowsValue.Len() == 0 {
+		return 0, nil
+	}
+	dialect := q.Dialect()
+	scope := sessionOf(q).scope
+	var conflictClause string
+	if conflictColumns != nil {
+		var err error
+		if conflictClause, err = upsertClause(dialect, table, columns, conflictColumns, scope); err != nil {
+			return 0, err
+		}
+	}
+
+	batchSize := max(dialect.maxParams()/len(columns), 1)
+	if maxRows := dialect.maxValuesRows(); maxRows > 0 {
+		batchSize = min(batchSize, maxRows)
+	}
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	prefix := "INSERT INTO " + table + " (" + strings.Join(names, ", ") + ") VALUES "
+
+	now := time.Now()
+	var affected int64
+	insert := func(q NamedQuerier) error {
+		// a retried transaction starts over
+		affected = 0
+		for start := 0; start < rowsValue.Len(); start += batchSize {
+			end := min(start+batchSize, rowsValue.Len())
+			args := make(map[string]any, (end-start)*len(columns))
+			var sb strings.Builder
+			sb.WriteString(prefix)
+			for r := start; r < end; r++ {
+				row := reflectx.DeRefValue(rowsValue.Index(r))
+				if !row.IsValid() {
+					return errors.New("row " + strconv.Itoa(r) + " is nil")
+				}
+				if err := scope.prepareInsert(row, columns, now); err != nil {
+					return err
+				}
+				if r > start {
+					sb.WriteString(", ")
+				}
+				sb.WriteByte('(')
+				for i, c := range columns {
+					if i > 0 {
+						sb.WriteString(", ")
+					}
+					name := "r" + strconv.Itoa(r-start) + "_" + strconv.Itoa(i)
+					args[name] = c.argValue(row)
+					sb.WriteString(":" + name)
+				}
+				sb.WriteByte(')')
+			}
+			sb.WriteString(conflictClause)
+			result, err := q.ExecContext(ctx, sb.String(), args)
+			if err != nil {
+				return err
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			affected += n
+		}
+		return nil
+	}
+	if runner, ok := q.(TxRunner); ok && rowsValue.Len() > batchSize {
+		err := runner.InTx(ctx, func(tx *Transaction) error {
+			return insert(tx)
+		})
+		if err != nil {
+			return 0, err
+		}
+		return affected, nil
+	}
+	if err := insert(q); err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// insertColumns returns the columns of t which are inserted.
+func insertColumns(t reflect.Type) []structColumn {
+	var columns []structColumn
+	for _, c := range mappingOf(t).structColumns() {
+		if !c.has("auto") && !c.has("readonly") {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// upsertClause renders the clause updating the columns which are not part of conflictColumns on conflict. If scope
+// restricts the tenant, only conflicting rows of the tenant are updated.
+func upsertClause(dialect Dialect, table string, columns []structColumn, conflictColumns []string, scope Scope) (string, error) {
+	for _, c := range conflictColumns {
+		if !identifierRegex.MatchString(c) {
+			return "", errors.New("invalid conflict column '" + c + "'")
+		}
+	}
+	var updates []string
+	var tenant string
+	for _, c := range columns {
+		if c.has("tenant") && scope.Tenant != nil {
+			tenant = c.name
+		}
+		// conflicting rows keep their creation time and tenant
+		if slices.Contains(conflictColumns, c.name) || c.has("created") || c.has("tenant") {
+			continue
+		}
+		switch dialect {
+		case MySQL:
+			updates = append(updates, c.name+" = VALUES("+c.name+")")
+		default:
+			updates = append(updates, c.name+" = excluded."+c.name)
+		}
+	}
+	switch dialect {
+	case SQLite, Postgres:
+		clause := " ON CONFLICT (" + strings.Join(conflictColumns, ", ") + ")"
+		if len(updates) == 0 {
+			return clause + " DO NOTHING", nil
+		}
+		clause += " DO UPDATE SET " + strings.Join(updates, ", ")
+		if tenant != "" {
+			clause += " WHERE " + table + "." + tenant + " = excluded." + tenant
+		}
+		return clause, nil
+	case MySQL:
+		if tenant != "" {
+			return "", errors.New("upsert scoped by tenant is not supported by " + dialect.String())
+		}
+		if len(updates) == 0 {
+			updates = append(updates, conflictColumns[0]+" = "+conflictColumns[0])
+		}
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", "), nil
+	default:
+		return "", errors.New("upsert is not supported by " + dialect.String())
+	}
+}
@@ -0,0 +1,181 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+type insertPerson struct {
+	ID   uint `db:"id,auto"`
+	Name string
+	Age  *int
+	Note string `db:"-"`
+}
+
+func TestInsertMany(t *testing.T) {
+	db, err := Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE person (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL UNIQUE, age INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	age := 42
+	rows := make([]*insertPerson, 1200)
+	for i := range rows {
+		rows[i] = &insertPerson{Name: "p" + string(rune('a'+i%26)) + string(rune('a'+i/26)), Age: &age}
+	}
+	rows[0].Age = nil
+	n, err := InsertMany(db, "person", rows)
+	if err != nil {
+		t.Fatalf("InsertMany() error = %v", err)
+	}
+	if n != int64(len(rows)) {
+		t.Fatalf("InsertMany() = %d, want %d", n, len(rows))
+	}
+
+	newAge := 7
+	if _, err := Upsert(db, "person", []insertPerson{{Name: "paa", Age: &newAge}, {Name: "new", Age: &newAge}}, "name"); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	var got []*insertPerson
+	if err := db.Select(&got, "SELECT * FROM person WHERE name IN (:names) ORDER BY name", map[string]any{"names": []string{"new", "paa", "pba"}}); err != nil {
+		t.Fatal(err)
+	}
+	want := []*insertPerson{{Name: "new", Age: &newAge}, {Name: "paa", Age: &newAge}, {Name: "pba", Age: &age}}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(insertPerson{}, "ID")); diff != "" {
+		t.Errorf("Upsert() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUpsert_Dialect(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialect   Dialect
+		conflict  []string
+		wantQuery string
+		wantErr   bool
+	}{{
+		name:      "postgres",
+		dialect:   Postgres,
+		conflict:  []string{"name"},
+		wantQuery: "INSERT INTO person (name, age) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET age = excluded.age",
+	}, {
+		name:      "postgres nothing to update",
+		dialect:   Postgres,
+		conflict:  []string{"name", "age"},
+		wantQuery: "INSERT INTO person (name, age) VALUES ($1, $2) ON CONFLICT (name, age) DO NOTHING",
+	}, {
+		name:      "mysql",
+		dialect:   MySQL,
+		conflict:  []string{"name"},
+		wantQuery: "INSERT INTO person (name, age) VALUES (?, ?) ON DUPLICATE KEY UPDATE age = VALUES(age)",
+	}, {
+		name:     "sqlserver",
+		dialect:  SQLServer,
+		conflict: []string{"name"},
+		wantErr:  true,
+	}, {
+		name:     "invalid conflict column",
+		dialect:  Postgres,
+		conflict: []string{"name; DROP TABLE person"},
+		wantErr:  true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantErr {
+				mock.ExpectExec(tt.wantQuery).WithArgs("Hans", nil).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			_, err = Upsert(NewDB(sqlDB, WithDialect(tt.dialect)), "person", []insertPerson{{Name: "Hans"}}, tt.conflict...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Upsert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+type taggedItem struct {
+	_    struct{} `db:"items,table"`
+	ID   int64    `db:"id,pk"`
+	Tags []string `db:"tags"`
+}
+
+// passThroughConverter passes slices to the driver like drivers supporting arrays do.
+type passThroughConverter struct{}
+
+func (passThroughConverter) ConvertValue(v any) (driver.Value, error) {
+	return v, nil
+}
+
+func TestInsertMany_SliceColumn(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual), sqlmock.ValueConverterOption(passThroughConverter{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectExec("INSERT INTO items (id, tags) VALUES (?, ?), (?, ?)").
+		WithArgs(int64(1), []string{"a", "b"}, int64(2), []string{}).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO items (id, tags) VALUES (?, ?)").
+		WithArgs(int64(3), []string{"c"}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	db := NewDB(sqlDB, WithDialect(MySQL))
+	if _, err := InsertMany(db, "items", []taggedItem{{ID: 1, Tags: []string{"a", "b"}}, {ID: 2, Tags: []string{}}}); err != nil {
+		t.Fatalf("InsertMany() error = %v", err)
+	}
+	repo, err := NewRepository[taggedItem](db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create(context.Background(), &taggedItem{ID: 3, Tags: []string{"c"}}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type insertName struct {
+	Name string
+}
+
+func TestInsertMany_Retry(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 999 parameters per batch
+	rows := make([]insertName, 1000)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO names").WillReturnResult(sqlmock.NewResult(0, 999))
+	mock.ExpectExec("INSERT INTO names").WillReturnError(errors.New("database is locked"))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO names").WillReturnResult(sqlmock.NewResult(0, 999))
+	mock.ExpectExec("INSERT INTO names").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	db := NewDB(sqlDB, WithDialect(SQLite))
+	db.RetryPolicy = RetryPolicy{MaxAttempts: 2}
+	n, err := InsertMany(db, "names", rows)
+	if err != nil {
+		t.Fatalf("InsertMany() error = %v", err)
+	}
+	if n != int64(len(rows)) {
+		t.Errorf("InsertMany() = %d, want %d", n, len(rows))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,85 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"iter"
+	"reflect"
+	"strconv"
+)
+
+// RowsQuerier queries rows, it is implemented by DB and Transaction which bind named parameters.
+type RowsQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Iterate queries rows and scans them one by one into values of T, which may be a pointer. Rows are scanned
+// like Select does. The rows are closed when the iteration ends or is stopped early.
+// Errors are yielded with the zero value of T and end the iteration.
+func Iterate[T any](ctx context.Context, q RowsQuerier, query string, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		t := reflect.TypeFor[T]()
+		isPtr := t.Kind() == reflect.Ptr
+		if isPtr {
+			t = t.Elem()
+		}
+		kind := rowKindOf(t)
+		rows, err := q.QueryContext(ctx, query, args...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer func(rows *sql.Rows) {
+			_ = rows.Close()
+		}(rows)
+		cols, err := rows.Columns()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		if kind == scalarRow && len(cols) != 1 {
+			yield(zero, errors.New("scanning into "+t.String()+" requires a single column, got "+strconv.Itoa(len(cols))))
+			return
+		}
+		var fields []fieldPlan
+		if kind == structRow {
+			fields = mappingOf(t).fieldPlans(cols)
+			if sessionOf(q).strict {
+				if err := checkUnmappedColumns(t, cols, fields); err != nil {
+					yield(zero, err)
+					return
+				}
+			}
+		}
+		columns := make([]any, len(cols))
+		columnPointers := make([]any, len(cols))
+		for i := range columns {
+			columnPointers[i] = &columns[i]
+		}
+		for rows.Next() {
+			if err := rows.Scan(columnPointers...); err != nil {
+				yield(zero, err)
+				return
+			}
+			elem := reflect.New(t)
+			if err := scanRow(kind, elem.Elem(), cols, fields, columnPointers); err != nil {
+				yield(zero, err)
+				return
+			}
+			var item T
+			if isPtr {
+				item = elem.Interface().(T)
+			} else {
+				item = elem.Elem().Interface().(T)
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
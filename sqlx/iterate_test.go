@@ -0,0 +1,106 @@
+package sqlx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIterate(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		inTx      bool
+		wantNames []string
+	}{{
+		name:      "all",
+		wantNames: []string{"Maxima", "Ludger", "Hans"},
+	}, {
+		name:      "stop early",
+		limit:     1,
+		wantNames: []string{"Maxima"},
+	}, {
+		name:      "transaction",
+		inTx:      true,
+		wantNames: []string{"Maxima", "Ludger", "Hans"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := prepareDB(t)
+			db.DB.SetMaxOpenConns(1)
+			for _, name := range []string{"Maxima", "Ludger", "Hans"} {
+				if _, err := db.Exec("INSERT INTO test_table(name) VALUES(:name);", map[string]any{"name": name}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			iterate := func(q RowsQuerier) []string {
+				var gotNames []string
+				for row, err := range Iterate[*testStruct](context.Background(), q, "SELECT * FROM test_table WHERE name <> :name ORDER BY id", map[string]any{"name": "Nobody"}) {
+					if err != nil {
+						t.Fatalf("Iterate() error = %v", err)
+					}
+					gotNames = append(gotNames, row.Name)
+					if len(gotNames) == tt.limit {
+						break
+					}
+				}
+				return gotNames
+			}
+			var gotNames []string
+			if tt.inTx {
+				err := db.InTx(context.Background(), func(tx *Transaction) error {
+					gotNames = iterate(tx)
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("InTx() error = %v", err)
+				}
+			} else {
+				gotNames = iterate(db)
+			}
+			if diff := cmp.Diff(tt.wantNames, gotNames); diff != "" {
+				t.Errorf("Iterate() mismatch (-want +got):\n%s", diff)
+			}
+			// the single connection is only available if the rows have been closed
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			if err := db.PingContext(ctx); err != nil {
+				t.Fatalf("PingContext() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestIterate_Value(t *testing.T) {
+	db := prepareDB(t)
+	if _, err := db.Exec("INSERT INTO test_table(name) VALUES('Hans');"); err != nil {
+		t.Fatal(err)
+	}
+	var got []testStruct
+	for row, err := range Iterate[testStruct](context.Background(), db, "SELECT * FROM test_table") {
+		if err != nil {
+			t.Fatalf("Iterate() error = %v", err)
+		}
+		got = append(got, row)
+	}
+	if diff := cmp.Diff([]testStruct{{ID: 1, Name: "Hans"}}, got); diff != "" {
+		t.Errorf("Iterate() mismatch (-want +got):\n%s", diff)
+	}
+	var gotNames []string
+	for name, err := range Iterate[string](context.Background(), db, "SELECT name FROM test_table") {
+		if err != nil {
+			t.Fatalf("Iterate() error = %v", err)
+		}
+		gotNames = append(gotNames, name)
+	}
+	if diff := cmp.Diff([]string{"Hans"}, gotNames); diff != "" {
+		t.Errorf("Iterate() mismatch (-want +got):\n%s", diff)
+	}
+	for _, err := range Iterate[string](context.Background(), db, "SELECT * FROM test_table") {
+		if err == nil {
+			t.Fatal("Iterate() expected error for multiple columns")
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package sqlx
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vloryan/go-libs/reflectx"
+	"github.com/vloryan/go-libs/stringx"
+)
+
+// tagOptions are the known options of db tags. The first option of a tag is a legacy alias of the column
+// unless it is one of them.
+var tagOptions = map[string]bool{
+	"auto":      true,
+	"pk":        true,
+	"readonly":  true,
+	"version":   true,
+	"table":     true,
+	"sensitive": true,
+	"json":      true,
+	"tenant":    true,
+	"deleted":   true,
+	"created":   true,
+	"updated":   true,
+}
+
+// columnAlias returns the legacy column alias of tag.
+func columnAlias(tag reflectx.FieldTag) string {
+	if len(tag.Opts) == 0 || tagOptions[tag.Opts[0]] || strings.Contains(tag.Opts[0], "=") {
+		return ""
+	}
+	return tag.Opts[0]
+}
+
+// tagOption returns the value of the option key=value of tag.
+func tagOption(tag reflectx.FieldTag, key string) (string, bool) {
+	for _, opt := range tag.Opts {
+		if value, ok := strings.CutPrefix(opt, key+"="); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// structMapping is the mapping plan of a struct type. It caches the index paths of the fields columns and
+// named parameters map to.
+type structMapping struct {
+	t       reflect.Type
+	columns sync.Map // column name -> fieldPlan
+
+	fieldsOnce sync.Once
+	fields     []structColumn
+}
+
+// structColumn is a column of a struct type, derived from an exported field.
+type structColumn struct {
+	name  string
+	index []int
+	opts  []string
+}
+
+func (c structColumn) has(opt string) bool {
+	return slices.Contains(c.opts, opt)
+}
+
+// argValue returns the value of the column in row as named arg, see fieldPlan.argValue. Unlike named args of
+// structs, slices are not expanded.
+func (c structColumn) argValue(row reflect.Value) any {
+	return columnArg{value: fieldPlan{index: c.index, sensitive: c.has("sensitive"), json: c.has("json")}.argValue(row)}
+}
+
+var structMappings sync.Map // reflect.Type -> *structMapping
+
+func mappingOf(t reflect.Type) *structMapping {
+	if m, ok := structMappings.Load(t); ok {
+		return m.(*structMapping)
+	}
+	m, _ := structMappings.LoadOrStore(t, &structMapping{t: t})
+	return m.(*structMapping)
+}
+
+// fieldPlan is the field a column maps to.
+type fieldPlan struct {
+	// index is the index path of the field, nil if no field matches
+	index []int
+	// sensitive is set by the "sensitive" tag option, values of such fields are redacted in QueryEvent
+	sensitive bool
+	// json is set by the "json" tag option, values of such fields are stored as JSON documents
+	json bool
+}
+
+// argValue returns the value of the field in row as named arg, marked as Sensitive and bound as JSON as
+// configured by the tag options.
+func (p fieldPlan) argValue(row reflect.Value) any {
+	value := fieldValueByIndex(row, p.index)
+	if p.json {
+		value = JSON[any]{V: value}
+	}
+	if p.sensitive {
+		value = Sensitive(value)
+	}
+	return value
+}
+
+// field returns the field column maps to. Segments of the column separated by '.' address fields of nested
+// structs, segments without a matching field are skipped.
+func (m *structMapping) field(column string) fieldPlan {
+	if plan, ok := m.columns.Load(column); ok {
+		return plan.(fieldPlan)
+	}
+	plan := fieldPlan{index: resolveFieldIndex(m.t, column)}
+	if plan.index != nil {
+		tag := reflectx.Tag(m.t.FieldByIndex(plan.index), "db")
+		plan.sensitive = tag.Has("sensitive")
+		plan.json = tag.Has("json")
+	}
+	m.columns.Store(column, plan)
+	return plan
+}
+
+// fieldIndex returns the index path of the field column maps to, nil if there is none.
+func (m *structMapping) fieldIndex(column string) []int {
+	return m.field(column).index
+}
+
+func (m *structMapping) fieldPlans(columns []string) []fieldPlan {
+	plans := make([]fieldPlan, len(columns))
+	for i, column := range columns {
+		plans[i] = m.field(column)
+	}
+	return plans
+}
+
+// structColumns returns the columns of the exported fields of the struct, including those of embedded structs and
+// structs with a prefix option, e.g. `db:",prefix=addr_"`. The column is the db tag or the snake cased field name,
+// fields tagged with "-" are skipped.
+func (m *structMapping) structColumns() []structColumn {
+	m.fieldsOnce.Do(func() {
+		m.fields = collectColumns(m.t, nil, "")
+	})
+	return m.fields
+}
+
+func collectColumns(t reflect.Type, index []int, prefix string) []structColumn {
+	var columns []structColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		dbTag := reflectx.Tag(field, "db")
+		if dbTag.Value == "-" {
+			continue
+		}
+		fieldIndex := append(slices.Clone(index), i)
+		fieldPrefix, hasPrefix := tagOption(dbTag, "prefix")
+		if (field.Anonymous && dbTag.Value == "") || hasPrefix {
+			if ft := reflectx.DeRef(field.Type); ft.Kind() == reflect.Struct {
+				columns = append(columns, collectColumns(ft, fieldIndex, prefix+fieldPrefix)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name := dbTag.Value
+		if name == "" {
+			name = stringx.ToSnakeCase(field.Name)
+		}
+		columns = append(columns, structColumn{name: prefix + name, index: fieldIndex, opts: dbTag.Opts})
+	}
+	return columns
+}
+
+func resolveFieldIndex(t reflect.Type, column string) []int {
+	var index []int
+	segments := strings.Split(column, ".")
+	current := t
+	for i, segment := range segments {
+		fieldIndex, fieldType, ok := findFieldIndex(current, segment)
+		if !ok {
+			if i == len(segments)-1 {
+				return nil
+			}
+			continue
+		}
+		index = append(index, fieldIndex...)
+		current = reflectx.DeRef(fieldType)
+	}
+	return index
+}
+
+// findFieldIndex finds the field of t matching column, searching embedded structs after the field embedding them.
+// Columns starting with the prefix option of a struct field are searched in the struct without the prefix.
+func findFieldIndex(t reflect.Type, column string) ([]int, reflect.Type, bool) {
+	if t.Kind() != reflect.Struct {
+		return nil, nil, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		dbTag := reflectx.Tag(field, "db")
+		if dbTag.Value == "-" {
+			continue
+		}
+		if (field.IsExported() || field.Anonymous) && matchesColumn(field, column) {
+			return []int{i}, field.Type, true
+		}
+		ft := reflectx.DeRef(field.Type)
+		if ft.Kind() != reflect.Struct {
+			continue
+		}
+		nested := column
+		if prefix, ok := tagOption(dbTag, "prefix"); ok {
+			var hasPrefix bool
+			if nested, hasPrefix = strings.CutPrefix(column, prefix); !hasPrefix {
+				continue
+			}
+		} else if !field.Anonymous {
+			continue
+		}
+		if index, fieldType, ok := findFieldIndex(ft, nested); ok {
+			return append([]int{i}, index...), fieldType, true
+		}
+	}
+	return nil, nil, false
+}
+
+func matchesColumn(field reflect.StructField, column string) bool {
+	dbTag := reflectx.Tag(field, "db")
+	if dbTag.Value != "" && (dbTag.Value == column || columnAlias(dbTag) == column) {
+		return true
+	}
+	return strings.EqualFold(field.Name, stringx.ToCamelCase(column))
+}
+
+// fieldByIndexAlloc returns the field of the struct v at index, allocating nil pointers on the way.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, errors.New("field of type '" + v.Type().String() + "' can not be allocated")
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// existingFieldByIndex returns the field of the struct v at index, false if it is behind a nil pointer.
+func existingFieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// fieldValueByIndex returns the value of the field of the struct v at index, nil if it is behind or is a nil pointer.
+func fieldValueByIndex(v reflect.Value, index []int) any {
+	f, ok := existingFieldByIndex(v, index)
+	if !ok || (f.Kind() == reflect.Ptr && f.IsNil()) {
+		return nil
+	}
+	return f.Interface()
+}
+
+// scanFields sets the fields of the struct dest to the scanned column values, columns without field are
+// skipped. NULL values zero the fields, fields behind nil pointers are left unset.
+func scanFields(fields []fieldPlan, columnPointers []any, dest reflect.Value) error {
+	for i, f := range fields {
+		if f.index == nil {
+			continue
+		}
+		val := *columnPointers[i].(*any)
+		var field reflect.Value
+		if val == nil {
+			var ok bool
+			if field, ok = existingFieldByIndex(dest, f.index); !ok {
+				continue
+			}
+		} else {
+			var err error
+			if field, err = fieldByIndexAlloc(dest, f.index); err != nil {
+				return err
+			}
+		}
+		if f.json && val != nil {
+			field.SetZero()
+			if err := unmarshalJSONColumn(val, field.Addr().Interface()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := setColumnValue(field, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setColumnValue sets v to the scanned column value val, using sql.Scanner if v implements it. NULL sets
+// pointers to nil and other values to their zero value, unless they implement sql.Scanner.
+func setColumnValue(v reflect.Value, val any) error {
+	if v.Kind() == reflect.Ptr {
+		if val == nil {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setColumnValue(v.Elem(), val)
+	}
+	if scanner, ok := v.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(val)
+	}
+	return reflectx.SetFieldValue(v, val)
+}
+
+// checkUnmappedColumns returns an error naming the columns without field in t.
+func checkUnmappedColumns(t reflect.Type, columns []string, fields []fieldPlan) error {
+	var unmapped []string
+	for i, f := range fields {
+		if f.index == nil {
+			unmapped = append(unmapped, columns[i])
+		}
+	}
+	if len(unmapped) == 0 {
+		return nil
+	}
+	return errors.New("no fields of " + t.String() + " match the columns " + strings.Join(unmapped, ", "))
+}
+
+type rowKind int
+
+const (
+	structRow rowKind = iota
+	mapRow
+	scalarRow
+)
+
+var (
+	timeType    = reflect.TypeFor[time.Time]()
+	scannerType = reflect.TypeFor[sql.Scanner]()
+	rowMapType  = reflect.TypeFor[map[string]any]()
+)
+
+// rowKindOf returns how a row is scanned into t. Structs implementing sql.Scanner and time.Time are scalars.
+func rowKindOf(t reflect.Type) rowKind {
+	switch {
+	case t.Kind() == reflect.Struct && t != timeType && !reflect.PointerTo(t).Implements(scannerType):
+		return structRow
+	case t.ConvertibleTo(rowMapType) && t.Kind() == reflect.Map:
+		return mapRow
+	default:
+		return scalarRow
+	}
+}
+
+// scanRow sets v to the scanned row.
+func scanRow(kind rowKind, v reflect.Value, cols []string, fields []fieldPlan, columnPointers []any) error {
+	switch kind {
+	case structRow:
+		return scanFields(fields, columnPointers, v)
+	case mapRow:
+		m := make(map[string]any, len(cols))
+		for i, col := range cols {
+			m[col] = *columnPointers[i].(*any)
+		}
+		v.Set(reflect.ValueOf(m).Convert(v.Type()))
+		return nil
+	default:
+		return setColumnValue(v, *columnPointers[0].(*any))
+	}
+}
@@ -0,0 +1,330 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/vloryan/go-libs/reflectx"
+	"github.com/vloryan/go-libs/stringx"
+)
+
+type mappingAddress struct {
+	City string
+	Zip  string `db:"zip_code"`
+}
+
+type MappingBase struct {
+	ID uint `db:"id,auto"`
+}
+
+type mappingPerson struct {
+	*MappingBase
+	Name    string `db:"full_name,name"`
+	Secret  string `db:"-"`
+	Address *mappingAddress
+	hidden  string
+}
+
+func Test_structMapping_fieldIndex(t *testing.T) {
+	tests := []struct {
+		column string
+		want   []int
+	}{
+		{column: "id", want: []int{0, 0}},
+		{column: "full_name", want: []int{1}},
+		{column: "name", want: []int{1}},
+		{column: "auto", want: nil},
+		{column: "secret", want: nil},
+		{column: "hidden", want: nil},
+		{column: "address.city", want: []int{3, 0}},
+		{column: "address.zip_code", want: []int{3, 1}},
+		{column: "unknown.full_name", want: []int{1}},
+		{column: "address.unknown", want: nil},
+	}
+	mapping := mappingOf(reflect.TypeFor[mappingPerson]())
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			for range 2 {
+				if diff := cmp.Diff(tt.want, mapping.fieldIndex(tt.column)); diff != "" {
+					t.Fatalf("fieldIndex() mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func Test_scanFields(t *testing.T) {
+	cols := []string{"id", "name", "address.city", "address.zip_code", "unknown"}
+	values := []any{int64(7), "Hans", "Berlin", nil, "x"}
+	columnPointers := make([]any, len(values))
+	for i := range values {
+		columnPointers[i] = &values[i]
+	}
+	got := &mappingPerson{}
+	fields := mappingOf(reflect.TypeFor[mappingPerson]()).fieldPlans(cols)
+	if err := scanFields(fields, columnPointers, reflect.ValueOf(got).Elem()); err != nil {
+		t.Fatalf("scanFields() error = %v", err)
+	}
+	want := &mappingPerson{MappingBase: &MappingBase{ID: 7}, Name: "Hans", Address: &mappingAddress{City: "Berlin"}}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(mappingPerson{})); diff != "" {
+		t.Errorf("scanFields() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type benchmarkRow struct {
+	ID        uint
+	Name      string
+	Email     string `db:"mail"`
+	Age       int
+	City      string
+	CreatedBy string
+	Address   *mappingAddress
+}
+
+func benchmarkColumns() ([]string, []any) {
+	cols := []string{"id", "name", "mail", "age", "city", "created_by", "address.city", "address.zip_code"}
+	values := []any{int64(1), "Hans", "hans@example.com", int64(42), "Berlin", "admin", "Hamburg", "20095"}
+	columnPointers := make([]any, len(values))
+	for i := range values {
+		columnPointers[i] = &values[i]
+	}
+	return cols, columnPointers
+}
+
+func BenchmarkScan(b *testing.B) {
+	cols, columnPointers := benchmarkColumns()
+	b.Run("legacy", func(b *testing.B) {
+		for range b.N {
+			if err := legacyScanStruct(cols, columnPointers, &benchmarkRow{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("mapping", func(b *testing.B) {
+		fields := mappingOf(reflect.TypeFor[benchmarkRow]()).fieldPlans(cols)
+		for range b.N {
+			row := &benchmarkRow{}
+			if err := scanFields(fields, columnPointers, reflect.ValueOf(row).Elem()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkExtractParamArgs(b *testing.B) {
+	names := []string{"id", "name", "mail", "age", "city", "created_by", "address.city"}
+	row := &benchmarkRow{ID: 1, Name: "Hans", Email: "hans@example.com", Age: 42, City: "Berlin", Address: &mappingAddress{City: "Hamburg"}}
+	b.Run("legacy", func(b *testing.B) {
+		for range b.N {
+			if _, err := legacyExtractParamArgsFromStruct(row, names); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("mapping", func(b *testing.B) {
+		for range b.N {
+			if _, err := extractParamArgsFromStruct(row, names); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// legacyScanStruct is the implementation of scanning before mapping plans, kept as benchmark baseline.
+func legacyScanStruct(columnNames []string, columnPointers []any, dest any) error {
+	for i, colName := range columnNames {
+		val := columnPointers[i].(*any)
+		if *val == nil {
+			continue
+		}
+		currentField := reflect.ValueOf(dest)
+		fieldPath := strings.Split(colName, ".")
+		for i := 0; i < len(fieldPath); i++ {
+			field := reflectx.FindFieldFunc(currentField.Interface(), func(field reflect.StructField) bool {
+				dbTag := reflectx.Tag(field, "db")
+				if dbTag.Value != "" {
+					if dbTag.Value == fieldPath[i] {
+						return true
+					}
+					if len(dbTag.Opts) > 0 && dbTag.Opts[0] == fieldPath[i] {
+						return true
+					}
+				}
+				return strings.EqualFold(field.Name, stringx.ToCamelCase(fieldPath[i]))
+			})
+
+			if !field.IsValid() {
+				continue
+			}
+			if i == len(fieldPath)-1 {
+				pField := field
+				if pField.Kind() != reflect.Ptr {
+					pField = field.Addr()
+				}
+				if scanner, ok := pField.Interface().(sql.Scanner); ok {
+					if err := scanner.Scan(*val); err != nil {
+						return err
+					}
+				} else {
+					if err := reflectx.SetFieldValue(field, *val); err != nil {
+						return err
+					}
+				}
+			} else {
+				if field.Type().Kind() == reflect.Ptr && field.IsNil() {
+					field.Set(reflect.New(field.Type().Elem()))
+				}
+			}
+			currentField = field
+		}
+	}
+	return nil
+}
+
+// legacyExtractParamArgsFromStruct is the implementation of resolving named parameters before mapping plans,
+// kept as benchmark baseline.
+func legacyExtractParamArgsFromStruct(obj any, names []string) ([]any, error) {
+	paramArgs := make([]any, 0, len(names))
+	for _, name := range names {
+		nameParts := strings.Split(name, ".")
+		currObj := obj
+		for _, namePart := range nameParts {
+			field := reflectx.FindFieldFunc(currObj, func(field reflect.StructField) bool {
+				dbTag := reflectx.Tag(field, "db")
+				if dbTag.Value != "" {
+					if dbTag.Value == name {
+						return true
+					}
+					if len(dbTag.Opts) > 0 && dbTag.Opts[0] == namePart {
+						return true
+					}
+				}
+				return strings.EqualFold(field.Name, stringx.ToCamelCase(namePart))
+			})
+			if !field.IsValid() {
+				continue
+			}
+			if field.Kind() == reflect.Ptr && field.IsNil() {
+				currObj = nil
+				break
+			}
+			currObj = field.Interface()
+		}
+		paramArgs = append(paramArgs, currObj)
+	}
+	return paramArgs, nil
+}
+
+type nullAddress struct {
+	Street string
+	City   *string
+}
+
+type nullPerson struct {
+	ID       int64
+	Name     string
+	Nick     *string
+	Note     sql.NullString
+	Age      sql.Null[int64]
+	Home     nullAddress  `db:",prefix=home_"`
+	Work     *nullAddress `db:",prefix=work_"`
+	Unmapped string       `db:"-"`
+}
+
+func Test_DB_Select_Null(t *testing.T) {
+	db, err := Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE TABLE null_person (
+				id INTEGER PRIMARY KEY, name TEXT, nick TEXT, note TEXT, age INTEGER,
+				home_street TEXT, home_city TEXT, work_street TEXT, work_city TEXT
+			)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`INSERT INTO null_person VALUES
+				(1, 'Hans', 'hansi', 'note', 40, 'Main St', 'Berlin', 'Office St', 'Hamburg'),
+				(2, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nick, berlin, hamburg := "hansi", "Berlin", "Hamburg"
+	tests := []struct {
+		name string
+		id   int
+		want *nullPerson
+	}{{
+		name: "values",
+		id:   1,
+		want: &nullPerson{
+			ID: 1, Name: "Hans", Nick: &nick,
+			Note: sql.NullString{String: "note", Valid: true},
+			Age:  sql.Null[int64]{V: 40, Valid: true},
+			Home: nullAddress{Street: "Main St", City: &berlin},
+			Work: &nullAddress{Street: "Office St", City: &hamburg},
+		},
+	}, {
+		name: "nulls",
+		id:   2,
+		want: &nullPerson{ID: 2},
+	}}
+	// the struct is reused to ensure NULLs reset previous values
+	got := &nullPerson{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := db.Get(got, "SELECT * FROM null_person WHERE id = :id", map[string]any{"id": tt.id}); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if tt.id == 2 {
+				// fields behind nil pointers are not allocated for NULLs
+				got.Work = nil
+				if err := db.Get(got, "SELECT * FROM null_person WHERE id = :id", map[string]any{"id": tt.id}); err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	n, err := InsertMany(db, "null_person", []nullPerson{{ID: 3, Name: "Anna", Home: nullAddress{City: &berlin}}})
+	if err != nil || n != 1 {
+		t.Fatalf("InsertMany() = %d, %v", n, err)
+	}
+	city, err := SelectScalar[string](db, "SELECT home_city FROM null_person WHERE id = 3")
+	if err != nil || city != berlin {
+		t.Fatalf("SelectScalar() = %v, %v", city, err)
+	}
+}
+
+func Test_DB_StrictColumns(t *testing.T) {
+	db, err := Open("sqlite3", ":memory:", WithStrictColumns())
+	if err != nil {
+		t.Fatal(err)
+	}
+	createTestTable(t, db)
+	if _, err := db.Exec("INSERT INTO test_table(name) VALUES('Hans')"); err != nil {
+		t.Fatal(err)
+	}
+	var got []*testStruct
+	if err := db.Select(&got, "SELECT id, name FROM test_table"); err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	err = db.InTx(context.Background(), func(tx *Transaction) error {
+		return tx.Select(&got, "SELECT id, name, 1 AS unknown FROM test_table")
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("Select() error = %v, want unmapped column error", err)
+	}
+	for _, err := range Iterate[testStruct](context.Background(), db, "SELECT id, name, 1 AS unknown FROM test_table") {
+		if err == nil {
+			t.Fatal("Iterate() expected unmapped column error")
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/vloryan/go-libs/sqlx"
+)
+
+// ErrChecksumMismatch is returned if an applied migration has been changed.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+const DefaultTable = "schema_migrations"
+
+var tableRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type Direction string
+
+const (
+	Up   Direction = "up"
+	Down Direction = "down"
+)
+
+// Step is the execution of a migration in a direction.
+type Step struct {
+	Version   int64
+	Name      string
+	Direction Direction
+	SQL       string
+}
+
+// AppliedMigration is the record of an applied migration.
+type AppliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator applies migrations to a database and records them in a bookkeeping table.
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+	table      string
+	dryRun     bool
+}
+
+// Option configures a Migrator.
+type Option func(m *Migrator)
+
+// WithTable sets the bookkeeping table, DefaultTable by default.
+func WithTable(table string) Option {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// WithDryRun makes the migrator return the steps it would execute without executing them. A missing bookkeeping
+// table is treated as no migrations being applied.
+func WithDryRun() Option {
+	return func(m *Migrator) {
+		m.dryRun = true
+	}
+}
+
+// New creates a migrator for migrations, see Load.
+func New(db *sqlx.DB, migrations []Migration, opts ...Option) (*Migrator, error) {
+	m := &Migrator{db: db, migrations: migrations, table: DefaultTable}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if !tableRegex.MatchString(m.table) {
+		return nil, errors.New("invalid table '" + m.table + "'")
+	}
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i-1].Version >= migrations[i].Version {
+			return nil, errors.New("migrations are not in ascending order of their versions")
+		}
+	}
+	return m, nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) ([]Step, error) {
+	if len(m.migrations) == 0 {
+		return nil, nil
+	}
+	return m.To(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// To migrates the database to version by applying the pending migrations up to version and reverting the applied
+// ones after version. Version 0 reverts all migrations. Each migration runs in its own transaction, the executed
+// steps are returned. Before any step the checksums of the applied migrations are verified.
+func (m *Migrator) To(ctx context.Context, version int64) ([]Step, error) {
+	// the bookkeeping table is read right after writing it, replicas may lag behind
+	ctx = sqlx.ForcePrimary(ctx)
+	if version != 0 && m.find(version) == nil {
+		return nil, errors.New("unknown migration version " + strconv.FormatInt(version, 10))
+	}
+	exists := true
+	if m.dryRun {
+		var err error
+		if exists, err = m.tableExists(ctx); err != nil {
+			return nil, err
+		}
+	} else if err := m.createTable(ctx); err != nil {
+		return nil, err
+	}
+	var applied []AppliedMigration
+	if exists {
+		var err error
+		if applied, err = m.Applied(ctx); err != nil {
+			return nil, err
+		}
+	}
+	isApplied := make(map[int64]bool, len(applied))
+	for _, a := range applied {
+		isApplied[a.Version] = true
+		if mig := m.find(a.Version); mig != nil && mig.Checksum != a.Checksum {
+			return nil, fmt.Errorf("migration %d '%s': %w", a.Version, a.Name, ErrChecksumMismatch)
+		}
+	}
+
+	var steps []Step
+	for _, mig := range m.migrations {
+		if mig.Version <= version && !isApplied[mig.Version] {
+			steps = append(steps, Step{Version: mig.Version, Name: mig.Name, Direction: Up, SQL: mig.Up})
+		}
+	}
+	for i := len(applied) - 1; i >= 0; i-- {
+		a := applied[i]
+		if a.Version <= version {
+			continue
+		}
+		mig := m.find(a.Version)
+		if mig == nil || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d '%s' can not be reverted", a.Version, a.Name)
+		}
+		steps = append(steps, Step{Version: mig.Version, Name: mig.Name, Direction: Down, SQL: mig.Down})
+	}
+	if m.dryRun {
+		return steps, nil
+	}
+	for i, step := range steps {
+		if err := m.run(ctx, step); err != nil {
+			return steps[:i], fmt.Errorf("migration %d '%s' %s: %w", step.Version, step.Name, step.Direction, err)
+		}
+	}
+	return steps, nil
+}
+
+// Applied returns the applied migrations in ascending order of their versions. They are read from the primary.
+func (m *Migrator) Applied(ctx context.Context) ([]AppliedMigration, error) {
+	applied := make([]AppliedMigration, 0)
+	query := "SELECT version, name, checksum, applied_at FROM " + m.table + " ORDER BY version"
+	if err := m.db.SelectContext(sqlx.ForcePrimary(ctx), &applied, query); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// tableExists reports whether the bookkeeping table exists.
+func (m *Migrator) tableExists(ctx context.Context) (bool, error) {
+	var query string
+	switch m.db.Dialect() {
+	case sqlx.SQLite:
+		query = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = :table"
+	case sqlx.Postgres:
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = :table"
+	case sqlx.MySQL:
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = :table"
+	case sqlx.SQLServer:
+		query = "SELECT COUNT(*) FROM sys.tables WHERE object_id = OBJECT_ID(:table)"
+	default:
+		return false, errors.New("dry run is not supported by " + m.db.Dialect().String())
+	}
+	n, err := sqlx.SelectScalarContext[int](ctx, m.db, query, map[string]any{"table": m.table})
+	return n > 0, err
+}
+
+func (m *Migrator) createTable(ctx context.Context) error {
+	columns := " (version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, checksum VARCHAR(64) NOT NULL, applied_at "
+	query := "CREATE TABLE IF NOT EXISTS " + m.table + columns + "TIMESTAMP NOT NULL)"
+	if m.db.Dialect() == sqlx.SQLServer {
+		query = "IF OBJECT_ID(N'" + m.table + "', N'U') IS NULL CREATE TABLE " + m.table + columns + "DATETIME2 NOT NULL)"
+	}
+	_, err := m.db.ExecContext(ctx, query)
+	return err
+}
+
+// run executes step and records it in a transaction.
+func (m *Migrator) run(ctx context.Context, step Step) error {
+	return m.db.InTx(ctx, func(tx *sqlx.Transaction) error {
+		if _, err := tx.ExecContext(ctx, step.SQL); err != nil {
+			return err
+		}
+		if step.Direction == Down {
+			_, err := tx.ExecContext(ctx, "DELETE FROM "+m.table+" WHERE version = :version", map[string]any{"version": step.Version})
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "INSERT INTO "+m.table+" (version, name, checksum, applied_at) VALUES (:version, :name, :checksum, :applied_at)", map[string]any{
+			"version":    step.Version,
+			"name":       step.Name,
+			"checksum":   m.find(step.Version).Checksum,
+			"applied_at": time.Now().UTC(),
+		})
+		return err
+	})
+}
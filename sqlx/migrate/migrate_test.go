@@ -0,0 +1,204 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/vloryan/go-libs/sqlx"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"migrations/0001_create_people.up.sql":   {Data: []byte("CREATE TABLE people (id INTEGER PRIMARY KEY, name TEXT NOT NULL);")},
+		"migrations/0001_create_people.down.sql": {Data: []byte("DROP TABLE people;")},
+		"migrations/0002_add_age.up.sql":         {Data: []byte("ALTER TABLE people ADD COLUMN age INTEGER;")},
+		"migrations/0002_add_age.down.sql":       {Data: []byte("ALTER TABLE people DROP COLUMN age;")},
+		"migrations/0003_create_pets.up.sql":     {Data: []byte("CREATE TABLE pets (id INTEGER PRIMARY KEY);\nCREATE INDEX pets_id ON pets(id);")},
+		"migrations/0003_create_pets.down.sql":   {Data: []byte("DROP TABLE pets;")},
+		"migrations/README.md":                   {Data: []byte("ignored")},
+	}
+}
+
+func openDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.DB.SetMaxOpenConns(1)
+	return db
+}
+
+func newMigrator(t *testing.T, db *sqlx.DB, fsys fstest.MapFS, opts ...Option) *Migrator {
+	migrations, err := Load(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	m, err := New(db, migrations, opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return m
+}
+
+func stepNames(steps []Step) []string {
+	got := make([]string, len(steps))
+	for i, s := range steps {
+		got[i] = s.Name + " " + string(s.Direction)
+	}
+	return got
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	m := newMigrator(t, db, testFS())
+
+	steps, err := m.To(ctx, 2)
+	if err != nil {
+		t.Fatalf("To() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"create_people up", "add_age up"}, stepNames(steps)); diff != "" {
+		t.Fatalf("To() mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := db.Exec("INSERT INTO people (name, age) VALUES ('Hans', 40)"); err != nil {
+		t.Fatal(err)
+	}
+
+	steps, err = newMigrator(t, db, testFS(), WithDryRun()).Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() dry run error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"create_pets up"}, stepNames(steps)); diff != "" {
+		t.Fatalf("Up() dry run mismatch (-want +got):\n%s", diff)
+	}
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		t.Fatalf("Applied() error = %v", err)
+	}
+	if len(applied) != 2 || applied[1].Version != 2 || applied[1].AppliedAt.IsZero() {
+		t.Fatalf("Applied() = %v", applied)
+	}
+
+	if steps, err = m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"create_pets up"}, stepNames(steps)); diff != "" {
+		t.Fatalf("Up() mismatch (-want +got):\n%s", diff)
+	}
+	if steps, err = m.Up(ctx); err != nil || len(steps) != 0 {
+		t.Fatalf("Up() = %v, %v, want no steps", steps, err)
+	}
+
+	if steps, err = m.To(ctx, 1); err != nil {
+		t.Fatalf("To() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"create_pets down", "add_age down"}, stepNames(steps)); diff != "" {
+		t.Fatalf("To() mismatch (-want +got):\n%s", diff)
+	}
+	var people []struct{ Name string }
+	if err := db.Select(&people, "SELECT * FROM people"); err != nil || len(people) != 1 {
+		t.Fatalf("Select() = %v, %v", people, err)
+	}
+	if steps, err = m.To(ctx, 0); err != nil || len(steps) != 1 {
+		t.Fatalf("To() = %v, %v", steps, err)
+	}
+}
+
+func TestMigrator_Drift(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	if _, err := newMigrator(t, db, testFS()).Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	fsys := testFS()
+	fsys["migrations/0002_add_age.up.sql"] = &fstest.MapFile{Data: []byte("ALTER TABLE people ADD COLUMN years INTEGER;")}
+	if _, err := newMigrator(t, db, fsys).Up(ctx); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Up() error = %v, want %v", err, ErrChecksumMismatch)
+	}
+}
+
+func TestMigrator_Rollback(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	fsys := testFS()
+	fsys["migrations/0003_create_pets.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE pets (id INTEGER PRIMARY KEY);\nINSERT INTO unknown VALUES (1);")}
+	m := newMigrator(t, db, fsys)
+	steps, err := m.Up(ctx)
+	if err == nil {
+		t.Fatal("Up() expected error")
+	}
+	if len(steps) != 2 {
+		t.Fatalf("Up() steps = %v, want 2 executed", stepNames(steps))
+	}
+	applied, err := m.Applied(ctx)
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("Applied() = %v, %v", applied, err)
+	}
+	var tables []struct{ Name string }
+	if err := db.Select(&tables, "SELECT name FROM sqlite_master WHERE name = 'pets'"); err != nil || len(tables) != 0 {
+		t.Fatalf("pets table = %v, %v, want rolled back", tables, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0001_a.down.sql": {Data: []byte("DROP")},
+	}
+	if _, err := Load(fsys, "m"); err == nil {
+		t.Error("Load() expected error for missing up file")
+	}
+	fsys = fstest.MapFS{
+		"m/0001_a.up.sql": {Data: []byte("A")},
+		"m/1_b.up.sql":    {Data: []byte("B")},
+	}
+	if _, err := Load(fsys, "m"); err == nil {
+		t.Error("Load() expected error for duplicate version")
+	}
+}
+
+func TestMigrator_Replica(t *testing.T) {
+	ctx := context.Background()
+	replica := openDB(t)
+	db, err := sqlx.Open("sqlite3", ":memory:", sqlx.WithReplicas(replica.DB), sqlx.WithMaxOpenConns(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newMigrator(t, db, testFS())
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if applied, err := m.Applied(ctx); err != nil || len(applied) != 3 {
+		t.Fatalf("Applied() = %v, %v", applied, err)
+	}
+	if steps, err := m.Up(ctx); err != nil || len(steps) != 0 {
+		t.Fatalf("Up() = %v, %v, want no steps", steps, err)
+	}
+}
+
+func TestMigrator_DryRun(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	steps, err := newMigrator(t, db, testFS(), WithDryRun()).Up(ctx)
+	if err != nil {
+		t.Fatalf("Up() dry run error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"create_people up", "add_age up", "create_pets up"}, stepNames(steps)); diff != "" {
+		t.Fatalf("Up() dry run mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := db.Exec("CREATE TABLE " + DefaultTable + " (version TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newMigrator(t, db, testFS(), WithDryRun()).Up(ctx); err == nil {
+		t.Fatal("Up() dry run expected error for malformed table")
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newMigrator(t, db, testFS(), WithDryRun()).Up(ctx); err == nil {
+		t.Fatal("Up() dry run expected error for closed database")
+	}
+}
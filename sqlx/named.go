@@ -1,15 +1,25 @@
 package sqlx
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"reflect"
 	"strconv"
+	"strings"
 	"unicode"
 )
 
 type NamedQuerier interface {
 	Select(dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	Get(dest any, query string, args ...any) error
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
 	Exec(query string, args ...any) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	Dialect() Dialect
 }
 
 var allowedBindRunes = []*unicode.RangeTable{unicode.Letter, unicode.Digit}
@@ -25,62 +35,220 @@ const (
 // compileNamedQuery compiles a query with named parameters into a plain query with database specific parameter markers and collects
 // a list of names.
 func compileNamedQuery(qs []byte, paramMarker rune) (query string, names []string, err error) {
-	names = make([]string, 0, 10)
-	plainQuery := make([]byte, 0, len(qs))
+	parsed, err := parseNamedQuery(qs, false)
+	if err != nil {
+		return query, names, err
+	}
+	return parsed.render(paramMarker, nil), parsed.names, nil
+}
 
-	inName := false
-	last := len(qs) - 1
-	currentVar := 1
-	name := make([]byte, 0, 10)
+// bindNamedQuery compiles query for dialect and resolves the values of its named parameters from args[0]. Slices are
+// expanded into one parameter per element, an empty slice into NULL. An empty slice is an error for NOT IN lists,
+// which would match no rows. Args of queries without named parameters are passed as they are.
+func bindNamedQuery(query string, dialect Dialect, args []any) (string, []any, error) {
+	parsed, err := parseNamedQuery([]byte(query), dialect.backslashEscapes())
+	if err != nil {
+		return "", nil, err
+	}
+	return parsed.bind(dialect.paramMarker(), args)
+}
+
+func (parsed namedQuery) bind(paramMarker rune, args []any) (string, []any, error) {
+	if len(parsed.names) == 0 || len(args) == 0 {
+		return parsed.render(paramMarker, nil), args, nil
+	}
+	paramArgs, err := extractParamArgs(args[0], parsed.names)
+	if err != nil {
+		return "", nil, err
+	}
+	var counts []int
+	expanded := make([]any, 0, len(paramArgs))
+	for i, arg := range paramArgs {
+		if c, ok := arg.(columnArg); ok {
+			expanded = append(expanded, c.value)
+			continue
+		}
+		sensitive, isSensitive := arg.(sensitiveArg)
+		if isSensitive {
+			arg = sensitive.value
+		}
+		v := reflect.ValueOf(arg)
+		if !isExpandable(v) {
+			expanded = append(expanded, paramArgs[i])
+			continue
+		}
+		if counts == nil {
+			counts = make([]int, len(paramArgs))
+			for j := range counts {
+				counts[j] = 1
+			}
+		}
+		counts[i] = v.Len()
+		if v.Len() == 0 && isNotInList(parsed.parts, i) {
+			// NOT IN (NULL) matches no rows instead of all
+			return "", nil, errors.New("empty slice for NOT IN parameter '" + parsed.names[i] + "'")
+		}
+		for j := 0; j < v.Len(); j++ {
+			if isSensitive {
+				expanded = append(expanded, Sensitive(v.Index(j).Interface()))
+			} else {
+				expanded = append(expanded, v.Index(j).Interface())
+			}
+		}
+	}
+	return parsed.render(paramMarker, counts), expanded, nil
+}
+
+// isNotInList reports whether the parameter following parts[i] is an element of a NOT IN list, i.e. it is
+// preceded by "NOT IN (" and possibly other elements of the list.
+func isNotInList(parts []string, i int) bool {
+	for ; i >= 0; i-- {
+		part := parts[i]
+		open := strings.LastIndexByte(part, '(')
+		if strings.ContainsRune(part[open+1:], ')') {
+			return false
+		}
+		if open >= 0 {
+			fields := strings.Fields(strings.ToUpper(part[:open]))
+			return len(fields) >= 2 && fields[len(fields)-2] == "NOT" && fields[len(fields)-1] == "IN"
+		}
+	}
+	return false
+}
 
-	for i, b := range qs {
-		if b == ':' {
-			if inName {
-				err = errors.New("unexpected `:` while reading named param at " + strconv.Itoa(i))
-				return query, names, err
+// columnArg is the value of a column of an entity, which is bound as single value even if it is a slice.
+type columnArg struct {
+	value any
+}
+
+// isExpandable reports whether v is a slice or array which is bound element-wise. Byte slices and driver.Valuer
+// are bound as single value.
+func isExpandable(v reflect.Value) bool {
+	if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() == reflect.Uint8 {
+		return false
+	}
+	_, ok := v.Interface().(driver.Valuer)
+	return !ok
+}
+
+// namedQuery is a query split at its named parameters, parts[i] precedes names[i] and the last part follows the last name.
+type namedQuery struct {
+	parts []string
+	names []string
+}
+
+// parseNamedQuery splits a query at its named parameters. Quoted strings and identifiers, comments and '::' type casts are
+// kept unchanged, as is a ':' which is not followed by a name. If backslashEscapes is set, a backslash escapes the
+// following character of a quoted string as in MySQL.
+func parseNamedQuery(qs []byte, backslashEscapes bool) (namedQuery, error) {
+	parsed := namedQuery{names: make([]string, 0, 10)}
+	part := make([]byte, 0, len(qs))
+
+	for i := 0; i < len(qs); {
+		b := qs[i]
+		switch {
+		case b == '\'' || b == '"' || b == '`':
+			end := quoteEnd(qs, i, backslashEscapes && b != '`')
+			if end < 0 {
+				return parsed, errors.New("unterminated quoted string at " + strconv.Itoa(i))
 			}
-			inName = true
-			name = []byte{}
-		} else if inName {
-			isAllowedRune := unicode.IsOneOf(allowedBindRunes, rune(b)) || b == '_' || b == '.' || b == '[' || b == ']'
-			if isAllowedRune {
-				name = append(name, b)
+			part = append(part, qs[i:end]...)
+			i = end
+		case b == '-' && i+1 < len(qs) && qs[i+1] == '-':
+			end := bytes.IndexByte(qs[i:], '\n')
+			if end < 0 {
+				end = len(qs)
 			} else {
-				inName = false
+				end += i
+			}
+			part = append(part, qs[i:end]...)
+			i = end
+		case b == '/' && i+1 < len(qs) && qs[i+1] == '*':
+			end := bytes.Index(qs[i+2:], []byte("*/"))
+			if end < 0 {
+				return parsed, errors.New("unterminated comment at " + strconv.Itoa(i))
 			}
-			if i == last {
-				inName = false
+			end += i + 4
+			part = append(part, qs[i:end]...)
+			i = end
+		case b == ':' && i+1 < len(qs) && qs[i+1] == ':':
+			part = append(part, ':', ':')
+			i += 2
+		case b == ':' && i+1 < len(qs) && isNameStart(qs[i+1]):
+			end := i + 1
+			for end < len(qs) && isNameByte(qs[end]) {
+				end++
+			}
+			parsed.parts = append(parsed.parts, string(part))
+			parsed.names = append(parsed.names, string(qs[i+1:end]))
+			part = part[:0]
+			i = end
+		default:
+			part = append(part, b)
+			i++
+		}
+	}
+	parsed.parts = append(parsed.parts, string(part))
+	return parsed, nil
+}
+
+// quoteEnd returns the index following the closing quote of the quoted string starting at qs[i], or -1 if it is
+// unterminated.
+func quoteEnd(qs []byte, i int, backslashEscapes bool) int {
+	for j := i + 1; j < len(qs); j++ {
+		switch qs[j] {
+		case '\\':
+			if backslashEscapes {
+				j++
 			}
-			if !inName {
-				names = append(names, string(name))
-				switch paramMarker {
-				// oracle only supports named type bind vars even for positional
-				case NAMED:
-					plainQuery = append(plainQuery, ':')
-					plainQuery = append(plainQuery, name...)
-				case QUESTION, UNKNOWN:
-					plainQuery = append(plainQuery, '?')
-				case DOLLAR:
-					plainQuery = append(plainQuery, '$')
-					for _, b := range strconv.Itoa(currentVar) {
-						plainQuery = append(plainQuery, byte(b))
-					}
-					currentVar++
-				case AT:
-					plainQuery = append(plainQuery, '@', 'p')
-					for _, b := range strconv.Itoa(currentVar) {
-						plainQuery = append(plainQuery, byte(b))
-					}
-					currentVar++
-				}
+		case qs[i]:
+			return j + 1
+		}
+	}
+	return -1
+}
+
+// render joins the parts of the query with parameter markers. counts holds the number of markers of each name,
+// a count of 0 renders NULL. A nil counts renders one marker per name.
+func (q namedQuery) render(paramMarker rune, counts []int) string {
+	var sb strings.Builder
+	currentVar := 1
+	for i, name := range q.names {
+		sb.WriteString(q.parts[i])
+		count := 1
+		if counts != nil {
+			count = counts[i]
+		}
+		if count == 0 {
+			sb.WriteString("NULL")
+		}
+		for j := 0; j < count; j++ {
+			if j > 0 {
+				sb.WriteString(", ")
 			}
-			if !isAllowedRune {
-				plainQuery = append(plainQuery, b)
+			switch paramMarker {
+			// oracle only supports named type bind vars even for positional
+			case NAMED:
+				sb.WriteString(":" + name)
+			case QUESTION, UNKNOWN:
+				sb.WriteByte('?')
+			case DOLLAR:
+				sb.WriteString("$" + strconv.Itoa(currentVar))
+				currentVar++
+			case AT:
+				sb.WriteString("@p" + strconv.Itoa(currentVar))
+				currentVar++
 			}
-		} else {
-			plainQuery = append(plainQuery, b)
 		}
 	}
+	sb.WriteString(q.parts[len(q.parts)-1])
+	return sb.String()
+}
+
+func isNameStart(b byte) bool {
+	return unicode.IsLetter(rune(b)) || b == '_'
+}
 
-	return string(plainQuery), names, err
+func isNameByte(b byte) bool {
+	return unicode.IsOneOf(allowedBindRunes, rune(b)) || b == '_' || b == '.' || b == '[' || b == ']'
 }
@@ -14,6 +14,7 @@ func Test_compileNamedQuery(t *testing.T) {
 	tests := []struct {
 		name      string
 		args      args
+		wantQuery string
 		wantNames []string
 	}{{
 		name: "Question mark",
@@ -22,12 +23,63 @@ func Test_compileNamedQuery(t *testing.T) {
 			bindType: '?',
 		},
 		wantNames: []string{"name1", "name2", "name_3", "object.field"},
+	}, {
+		name: "Dollar",
+		args: args{
+			query:    "SELECT * FROM my_table WHERE col_a = :a AND col_b = :b",
+			bindType: DOLLAR,
+		},
+		wantQuery: "SELECT * FROM my_table WHERE col_a = $1 AND col_b = $2",
+		wantNames: []string{"a", "b"},
+	}, {
+		name: "At",
+		args: args{
+			query:    "SELECT * FROM my_table WHERE col_a = :a AND col_b = :b",
+			bindType: AT,
+		},
+		wantQuery: "SELECT * FROM my_table WHERE col_a = @p1 AND col_b = @p2",
+		wantNames: []string{"a", "b"},
+	}, {
+		name: "Quoted strings and identifiers",
+		args: args{
+			query:    `SELECT ':no', "col:x", ` + "`c:y`" + ` FROM my_table WHERE col_a = :a AND col_b = 'it''s :no'`,
+			bindType: DOLLAR,
+		},
+		wantQuery: `SELECT ':no', "col:x", ` + "`c:y`" + ` FROM my_table WHERE col_a = $1 AND col_b = 'it''s :no'`,
+		wantNames: []string{"a"},
+	}, {
+		name: "Comments",
+		args: args{
+			query:    "SELECT * -- where :no\nFROM my_table /* :no */ WHERE col_a = :a",
+			bindType: QUESTION,
+		},
+		wantQuery: "SELECT * -- where :no\nFROM my_table /* :no */ WHERE col_a = ?",
+		wantNames: []string{"a"},
+	}, {
+		name: "Casts",
+		args: args{
+			query:    "SELECT col_a::text FROM my_table WHERE col_b = :b::int AND col_c = :c",
+			bindType: DOLLAR,
+		},
+		wantQuery: "SELECT col_a::text FROM my_table WHERE col_b = $1::int AND col_c = $2",
+		wantNames: []string{"b", "c"},
+	}, {
+		name: "Colon without name",
+		args: args{
+			query:    "SELECT arr[1:2] FROM my_table WHERE col_a = :a:",
+			bindType: QUESTION,
+		},
+		wantQuery: "SELECT arr[1:2] FROM my_table WHERE col_a = ?:",
+		wantNames: []string{"a"},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			wantQuery := tt.args.query
-			for _, name := range tt.wantNames {
-				wantQuery = strings.Replace(wantQuery, ":"+name, string(tt.args.bindType), 1)
+			wantQuery := tt.wantQuery
+			if wantQuery == "" {
+				wantQuery = tt.args.query
+				for _, name := range tt.wantNames {
+					wantQuery = strings.Replace(wantQuery, ":"+name, string(tt.args.bindType), 1)
+				}
 			}
 			gotQuery, gotNames, err := compileNamedQuery([]byte(tt.args.query), tt.args.bindType)
 			if err != nil {
@@ -42,3 +94,80 @@ func Test_compileNamedQuery(t *testing.T) {
 		})
 	}
 }
+
+func Test_compileNamedQuery_Unterminated(t *testing.T) {
+	for _, query := range []string{"SELECT 'abc FROM my_table", "SELECT * /* FROM my_table"} {
+		if _, _, err := compileNamedQuery([]byte(query), QUESTION); err == nil {
+			t.Errorf("compileNamedQuery(%q) expected error", query)
+		}
+	}
+}
+
+func Test_bindNamedQuery(t *testing.T) {
+	gotQuery, gotArgs, err := bindNamedQuery("SELECT * FROM tab WHERE id IN (:ids) AND name = :name", Postgres, []any{map[string]any{"ids": []int{1, 2, 3}, "name": "x"}})
+	if err != nil {
+		t.Fatalf("bindNamedQuery() error = %v", err)
+	}
+	if want := "SELECT * FROM tab WHERE id IN ($1, $2, $3) AND name = $4"; gotQuery != want {
+		t.Errorf("bindNamedQuery() gotQuery = %v, want %v", gotQuery, want)
+	}
+	if want := []any{1, 2, 3, "x"}; !reflect.DeepEqual(gotArgs, want) {
+		t.Errorf("bindNamedQuery() gotArgs = %v, want %v", gotArgs, want)
+	}
+}
+
+func Test_bindNamedQuery_BackslashEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{name: "mysql", dialect: MySQL, query: `SELECT 'it\'s :x', "a\":x\\" FROM tab WHERE a = :a`, want: `SELECT 'it\'s :x', "a\":x\\" FROM tab WHERE a = ?`},
+		{name: "mysql backtick", dialect: MySQL, query: "SELECT `a\\` FROM tab WHERE a = :a", want: "SELECT `a\\` FROM tab WHERE a = ?"},
+		{name: "mysql unterminated", dialect: MySQL, query: `SELECT 'a\' FROM tab WHERE a = :a`, wantErr: true},
+		{name: "postgres", dialect: Postgres, query: `SELECT 'a\' FROM tab WHERE a = :a`, want: `SELECT 'a\' FROM tab WHERE a = $1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotArgs, err := bindNamedQuery(tt.query, tt.dialect, []any{map[string]any{"a": 1}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("bindNamedQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("bindNamedQuery() = %v, want %v", got, tt.want)
+			}
+			if want := []any{1}; !tt.wantErr && !reflect.DeepEqual(gotArgs, want) {
+				t.Errorf("bindNamedQuery() gotArgs = %v, want %v", gotArgs, want)
+			}
+		})
+	}
+}
+
+func Test_bindNamedQuery_EmptySlice(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{name: "in", query: "SELECT * FROM tab WHERE id IN (:ids)", want: "SELECT * FROM tab WHERE id IN (NULL)"},
+		{name: "not in", query: "SELECT * FROM tab WHERE id NOT IN (:ids)", wantErr: true},
+		{name: "not in without space", query: "SELECT * FROM tab WHERE id not in(:ids)", wantErr: true},
+		{name: "not in after elements", query: "SELECT * FROM tab WHERE id NOT IN (:a, 7, :ids)", wantErr: true},
+		{name: "in after not in", query: "SELECT * FROM tab WHERE id NOT IN (:a) AND id IN (:ids)", want: "SELECT * FROM tab WHERE id NOT IN (?) AND id IN (NULL)"},
+		{name: "not in subquery", query: "SELECT * FROM tab WHERE id NOT IN (SELECT id FROM other WHERE x IN (:ids))", want: "SELECT * FROM tab WHERE id NOT IN (SELECT id FROM other WHERE x IN (NULL))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := bindNamedQuery(tt.query, SQLite, []any{map[string]any{"a": 1, "ids": []int{}}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("bindNamedQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("bindNamedQuery() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
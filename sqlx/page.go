@@ -0,0 +1,130 @@
+package sqlx
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/vloryan/go-libs/reflectx"
+	"github.com/vloryan/go-libs/sqlx/pagination"
+	"github.com/vloryan/go-libs/stringx"
+)
+
+// SelectPage selects the rows of query addressed by page into dest, which must be a pointer to a slice of structs.
+// The sort keys of page must match columns of the struct, a leading '-' sorts descending.
+// page.TotalCount is set to the number of rows query returns without pagination, page.RowCount to the number of
+// selected rows. A nil page selects all rows.
+// Cursor pages are selected by their keyset, see selectCursorPage.
+func SelectPage(q NamedQuerier, dest any, query string, page *pagination.Page, args ...any) error {
+	return SelectPageContext(context.Background(), q, dest, query, page, args...)
+}
+
+func SelectPageContext(ctx context.Context, q NamedQuerier, dest any, query string, page *pagination.Page, args ...any) error {
+	if page == nil {
+		return q.SelectContext(ctx, dest, query, args...)
+	}
+	if page.IsCursor() {
+		return selectCursorPage(ctx, q, dest, query, page, args...)
+	}
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.Elem().Kind() != reflect.Slice {
+		return errors.New("dest is no pointer to slice")
+	}
+	elemType := reflectx.ElemTypeOf(dest, true)
+	if elemType.Kind() != reflect.Struct {
+		return errors.New("dest is no slice of structs")
+	}
+	baseQuery := trimQuery(query)
+	orderBy, err := orderByClause(elemType, page.Sort)
+	if err != nil {
+		return err
+	}
+	pagedQuery := baseQuery
+	limit := q.Dialect().limitClause(page.Limit, page.Offset)
+	if orderBy == "" && limit != "" && q.Dialect() == SQLServer {
+		orderBy = "ORDER BY (SELECT NULL)"
+	}
+	if orderBy != "" {
+		pagedQuery += " " + orderBy
+	}
+	if limit != "" {
+		pagedQuery += " " + limit
+	}
+	if err := q.SelectContext(ctx, dest, pagedQuery, args...); err != nil {
+		return err
+	}
+	page.RowCount = destValue.Elem().Len()
+	if page.Limit <= 0 && page.Offset == 0 {
+		page.TotalCount = destValue.Elem().Len()
+		return nil
+	}
+	count := &struct{ Count int }{}
+	if err := q.SelectContext(ctx, count, "SELECT COUNT(*) AS count FROM ("+baseQuery+") AS page_query", args...); err != nil {
+		return err
+	}
+	page.TotalCount = count.Count
+	return nil
+}
+
+func trimQuery(query string) string {
+	return strings.TrimRight(strings.TrimSpace(query), "; \t\n")
+}
+
+// orderByClause renders the ORDER BY clause for sorts. Each sort must match a column of t.
+func orderByClause(t reflect.Type, sorts []string) (string, error) {
+	if len(sorts) == 0 {
+		return "", nil
+	}
+	terms := make([]string, 0, len(sorts))
+	for _, sort := range sorts {
+		sort = strings.TrimSpace(sort)
+		direction := " ASC"
+		if strings.HasPrefix(sort, "-") {
+			direction = " DESC"
+			sort = sort[1:]
+		}
+		column, _, ok := sortField(t, sort)
+		if !ok {
+			return "", errors.New("invalid sort '" + sort + "'")
+		}
+		terms = append(terms, column+direction)
+	}
+	return "ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+// sortField returns the column name and the index of the field of t matching key,
+// following the same rules used to scan rows.
+func sortField(t reflect.Type, key string) (string, []int, bool) {
+	if key == "" {
+		return "", nil, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		dbTag := reflectx.Tag(field, "db")
+		if dbTag.Value == "-" {
+			continue
+		}
+		if field.Anonymous {
+			if ft := reflectx.DeRef(field.Type); ft.Kind() == reflect.Struct {
+				if column, index, ok := sortField(ft, key); ok {
+					return column, append([]int{i}, index...), true
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if dbTag.Value != "" && (dbTag.Value == key || columnAlias(dbTag) == key) {
+			return dbTag.Value, field.Index, true
+		}
+		if strings.EqualFold(field.Name, stringx.ToCamelCase(key)) {
+			if dbTag.Value != "" {
+				return dbTag.Value, field.Index, true
+			}
+			return stringx.ToSnakeCase(field.Name), field.Index, true
+		}
+	}
+	return "", nil, false
+}
@@ -0,0 +1,79 @@
+package sqlx
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/vloryan/go-libs/sqlx/pagination"
+)
+
+func TestSelectPage(t *testing.T) {
+	tests := []struct {
+		name      string
+		page      *pagination.Page
+		wantNames []string
+		wantTotal int
+		wantErr   bool
+	}{{
+		name:      "limitless",
+		page:      pagination.Limitless,
+		wantNames: []string{"Maxima", "Ludger", "Hans", "Anna"},
+	}, {
+		name:      "sort by",
+		page:      pagination.SortBy("name"),
+		wantNames: []string{"Anna", "Hans", "Ludger", "Maxima"},
+		wantTotal: 4,
+	}, {
+		name:      "descending",
+		page:      pagination.NewPage(0, 2, "-name"),
+		wantNames: []string{"Maxima", "Ludger"},
+		wantTotal: 4,
+	}, {
+		name:      "offset",
+		page:      pagination.NewPage(2, 2, "id"),
+		wantNames: []string{"Hans", "Anna"},
+		wantTotal: 4,
+	}, {
+		name:      "offset without limit",
+		page:      pagination.NewPage(3, -1, "id"),
+		wantNames: []string{"Anna"},
+		wantTotal: 4,
+	}, {
+		name:      "first",
+		page:      pagination.First("-id"),
+		wantNames: []string{"Anna"},
+		wantTotal: 4,
+	}, {
+		name:    "sort not whitelisted",
+		page:    pagination.SortBy("name; DROP TABLE test_table"),
+		wantErr: true,
+	}}
+	db := prepareDB(t)
+	for _, name := range []string{"Maxima", "Ludger", "Hans", "Anna"} {
+		if _, err := db.Exec("INSERT INTO test_table(name) VALUES(:name);", map[string]any{"name": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []*testStruct
+			err := db.SelectPage(&got, "SELECT * FROM test_table WHERE name <> :name;", tt.page, map[string]any{"name": "Nobody"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectPage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			gotNames := make([]string, len(got))
+			for i, p := range got {
+				gotNames[i] = p.Name
+			}
+			if diff := cmp.Diff(tt.wantNames, gotNames); diff != "" {
+				t.Errorf("SelectPage() mismatch (-want +got):\n%s", diff)
+			}
+			if tt.page != nil && tt.page.TotalCount != tt.wantTotal {
+				t.Errorf("SelectPage() TotalCount = %d, want %d", tt.page.TotalCount, tt.wantTotal)
+			}
+		})
+	}
+}
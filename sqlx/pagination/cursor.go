@@ -0,0 +1,91 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor positions a page by the sort key values of a row instead of an offset.
+type Cursor struct {
+	After  string `json:"after,omitempty"`
+	Before string `json:"before,omitempty"`
+	// Next and Prev are set after the page has been selected and point to the adjacent pages.
+	// They are empty if there is no such page.
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// IsCursor reports whether p is positioned by a cursor.
+func (p *Page) IsCursor() bool {
+	return p != nil && p.Cursor != nil
+}
+
+type timeValue struct {
+	Time time.Time `json:"time"`
+}
+
+// EncodeCursor encodes sort key values into an opaque cursor.
+func EncodeCursor(values ...any) (string, error) {
+	encoded := make([]any, len(values))
+	for i, v := range values {
+		switch tv := v.(type) {
+		case time.Time:
+			encoded[i] = timeValue{Time: tv}
+		case *time.Time:
+			if tv != nil {
+				encoded[i] = timeValue{Time: *tv}
+			}
+		default:
+			encoded[i] = v
+		}
+	}
+	b, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeCursor decodes the sort key values of cursor. Integral numbers are returned as int64, others as float64.
+func DecodeCursor(cursor string) ([]any, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	values := make([]any, len(raw))
+	for i, r := range raw {
+		if bytes.HasPrefix(r, []byte("{")) {
+			tv := timeValue{}
+			if err := json.Unmarshal(r, &tv); err != nil {
+				return nil, ErrInvalidCursor
+			}
+			values[i] = tv.Time
+			continue
+		}
+		d := json.NewDecoder(bytes.NewReader(r))
+		d.UseNumber()
+		var v any
+		if err := d.Decode(&v); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		if n, ok := v.(json.Number); ok {
+			if iv, err := strconv.ParseInt(n.String(), 10, 64); err == nil {
+				v = iv
+			} else if fv, err := n.Float64(); err == nil {
+				v = fv
+			}
+		}
+		values[i] = v
+	}
+	return values, nil
+}
@@ -0,0 +1,26 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEncodeDecodeCursor(t *testing.T) {
+	values := []any{int64(7), 1.5, "name", true, nil, time.Date(2024, 6, 26, 20, 33, 44, 0, time.UTC)}
+	cursor, err := EncodeCursor(values...)
+	if err != nil {
+		t.Fatalf("EncodeCursor() error = %v", err)
+	}
+	got, err := DecodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if diff := cmp.Diff(values, got); diff != "" {
+		t.Errorf("DecodeCursor() mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := DecodeCursor("not a cursor"); err != ErrInvalidCursor {
+		t.Errorf("DecodeCursor() error = %v, want %v", err, ErrInvalidCursor)
+	}
+}
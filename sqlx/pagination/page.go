@@ -5,6 +5,10 @@ type Page struct {
 	Limit      int      `json:"limit"`
 	Sort       []string `json:"sort"`
 	TotalCount int      `json:"totalCount"`
+	// RowCount is the number of rows of the selected page, it tells if there may be more rows if TotalCount
+	// is unknown.
+	RowCount int     `json:"rowCount"`
+	Cursor   *Cursor `json:"cursor,omitempty"`
 }
 
 func NewPage(offset, limit int, sorts ...string) *Page {
@@ -23,6 +27,16 @@ func First(sorts ...string) *Page {
 	}
 }
 
+// NewCursorPage creates a page of size rows positioned after or before the encoded sort key values of a row.
+// Sorts must identify rows uniquely, e.g. by ending with the primary key.
+func NewCursorPage(size int, after, before string, sorts ...string) *Page {
+	return &Page{
+		Limit:  size,
+		Sort:   sorts,
+		Cursor: &Cursor{After: after, Before: before},
+	}
+}
+
 func SortBy(sorts ...string) *Page {
 	return &Page{
 		Offset: 0,
@@ -33,23 +47,49 @@ func SortBy(sorts ...string) *Page {
 
 var Limitless *Page = nil
 
+// Next returns the page following p or nil if p is the last page. If TotalCount is unknown, i.e. 0, there is
+// a next page as long as p is full.
 func (p *Page) Next() *Page {
-	if p.Offset == p.TotalCount {
+	if p.Limit <= 0 {
+		return nil
+	}
+	if p.TotalCount > 0 && p.Offset+p.Limit >= p.TotalCount || p.TotalCount <= 0 && p.RowCount < p.Limit {
 		return nil
 	}
 	return &Page{
-		Offset: p.Offset + 1,
+		Offset: p.Offset + p.Limit,
 		Limit:  p.Limit,
 		Sort:   p.Sort,
 	}
 }
 
+// Prev returns the page preceding p or nil if p is the first page.
 func (p *Page) Prev() *Page {
-	if p.Offset == 0 {
+	if p.Offset <= 0 {
 		return nil
 	}
+	offset := 0
+	if p.Limit > 0 {
+		offset = max(p.Offset-p.Limit, 0)
+	}
 	return &Page{
-		Offset: p.Offset - 1,
+		Offset: offset,
+		Limit:  p.Limit,
+		Sort:   p.Sort,
+	}
+}
+
+// Last returns the last page of the result p is part of, nil if TotalCount is unknown, i.e. 0.
+func (p *Page) Last() *Page {
+	if p.TotalCount <= 0 {
+		return nil
+	}
+	offset := 0
+	if p.Limit > 0 {
+		offset = (p.TotalCount - 1) / p.Limit * p.Limit
+	}
+	return &Page{
+		Offset: offset,
 		Limit:  p.Limit,
 		Sort:   p.Sort,
 	}
@@ -0,0 +1,70 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPage_NextPrevLast(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     *Page
+		wantNext *Page
+		wantPrev *Page
+		wantLast *Page
+	}{{
+		name:     "first page",
+		page:     &Page{Offset: 0, Limit: 10, TotalCount: 25},
+		wantNext: &Page{Offset: 10, Limit: 10},
+		wantLast: &Page{Offset: 20, Limit: 10},
+	}, {
+		name:     "middle page",
+		page:     &Page{Offset: 10, Limit: 10, TotalCount: 25, Sort: []string{"id"}},
+		wantNext: &Page{Offset: 20, Limit: 10, Sort: []string{"id"}},
+		wantPrev: &Page{Offset: 0, Limit: 10, Sort: []string{"id"}},
+		wantLast: &Page{Offset: 20, Limit: 10, Sort: []string{"id"}},
+	}, {
+		name:     "last page",
+		page:     &Page{Offset: 20, Limit: 10, TotalCount: 25},
+		wantPrev: &Page{Offset: 10, Limit: 10},
+		wantLast: &Page{Offset: 20, Limit: 10},
+	}, {
+		name:     "unaligned offset",
+		page:     &Page{Offset: 5, Limit: 10, TotalCount: 25},
+		wantNext: &Page{Offset: 15, Limit: 10},
+		wantPrev: &Page{Offset: 0, Limit: 10},
+		wantLast: &Page{Offset: 20, Limit: 10},
+	}, {
+		name:     "full page of unknown total",
+		page:     &Page{Offset: 10, Limit: 10, RowCount: 10},
+		wantNext: &Page{Offset: 20, Limit: 10},
+		wantPrev: &Page{Offset: 0, Limit: 10},
+	}, {
+		name:     "partial page of unknown total",
+		page:     &Page{Offset: 10, Limit: 10, RowCount: 3},
+		wantPrev: &Page{Offset: 0, Limit: 10},
+	}, {
+		name:     "limitless with offset",
+		page:     &Page{Offset: 5, Limit: -1, TotalCount: 25},
+		wantPrev: &Page{Offset: 0, Limit: -1},
+		wantLast: &Page{Offset: 0, Limit: -1},
+	}, {
+		name:     "limitless",
+		page:     &Page{Offset: 0, Limit: -1, TotalCount: 25},
+		wantLast: &Page{Offset: 0, Limit: -1},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.wantNext, tt.page.Next()); diff != "" {
+				t.Errorf("Next() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantPrev, tt.page.Prev()); diff != "" {
+				t.Errorf("Prev() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantLast, tt.page.Last()); diff != "" {
+				t.Errorf("Last() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package sqlx
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -9,165 +10,226 @@ import (
 	"strings"
 
 	"github.com/vloryan/go-libs/reflectx"
-	"github.com/vloryan/go-libs/stringx"
 )
 
 type sqlQueryer interface {
-	Query(query string, args ...any) (*sql.Rows, error)
-	Exec(query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
 func Select(q sqlQueryer, dest any, query string, args ...any) error {
-	_query := query
-	paramArgs := args
-	if len(args) > 0 {
-		var names []string
-		var err error
-		_query, names, err = compileNamedQuery([]byte(query), '?')
-		if err != nil {
-			return err
-		}
-		if len(names) > 0 {
-			paramArgs, err = extractParamArgs(args[0], names)
-			if err != nil {
-				return err
-			}
-		}
+	return SelectContext(context.Background(), q, dest, query, args...)
+}
+
+// SelectContext selects the rows of query into dest. Named parameters are compiled for the dialect of q,
+// SQLite if q does not provide one.
+func SelectContext(ctx context.Context, q sqlQueryer, dest any, query string, args ...any) error {
+	return selectContext(ctx, q, sessionOf(q), dest, query, args...)
+}
+
+func selectContext(ctx context.Context, q sqlQueryer, s session, dest any, query string, args ...any) error {
+	rows, done, err := startQuery(ctx, q, s, query, args...)
+	if err != nil {
+		return err
+	}
+	_, err = unmarshalRows(rows, dest, s.strict)
+	if err == nil {
+		err = rows.Err()
 	}
+	_ = rows.Close()
+	done(err)
+	return err
+}
+
+// Get selects the first row of query into dest like Select does, sql.ErrNoRows is returned if there is none.
+func Get(q sqlQueryer, dest any, query string, args ...any) error {
+	return GetContext(context.Background(), q, dest, query, args...)
+}
+
+func GetContext(ctx context.Context, q sqlQueryer, dest any, query string, args ...any) error {
+	return getContext(ctx, q, sessionOf(q), dest, query, args...)
+}
 
-	rows, err := q.Query(_query, paramArgs...)
+func getContext(ctx context.Context, q sqlQueryer, s session, dest any, query string, args ...any) error {
+	if isRowSlice(reflectx.TypeOf(dest, true)) {
+		return errors.New("dest of get must not be a slice")
+	}
+	rows, done, err := startQuery(ctx, q, s, query, args...)
 	if err != nil {
 		return err
 	}
-	if rows.Err() != nil {
-		return rows.Err()
+	n, err := unmarshalRows(rows, dest, s.strict)
+	if err == nil {
+		err = rows.Err()
 	}
-	defer func(rows *sql.Rows) {
-		_ = rows.Close()
-	}(rows)
-	return unmarshalRows(rows, dest)
+	_ = rows.Close()
+	done(err)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
 }
 
-func unmarshalRows(rows *sql.Rows, dest any) error {
+// SelectScalar selects the single column of the first row of query, e.g. a COUNT(*). sql.ErrNoRows is returned
+// if there is no row.
+func SelectScalar[T any](q NamedQuerier, query string, args ...any) (T, error) {
+	return SelectScalarContext[T](context.Background(), q, query, args...)
+}
+
+func SelectScalarContext[T any](ctx context.Context, q NamedQuerier, query string, args ...any) (T, error) {
+	var v T
+	if err := q.GetContext(ctx, &v, query, args...); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+func queryContext(ctx context.Context, q sqlQueryer, s session, query string, args ...any) (*sql.Rows, error) {
+	rows, done, err := startQuery(ctx, q, s, query, args...)
+	if err == nil {
+		done(nil)
+	}
+	return rows, err
+}
+
+// startQuery queries rows like queryContext. done fires the after hooks with the final error of reading the rows,
+// it must be called unless an error is returned.
+func startQuery(ctx context.Context, q sqlQueryer, s session, query string, args ...any) (*sql.Rows, func(err error), error) {
+	_query, paramArgs, err := s.bind(query, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	driverArgs, eventArgs := unwrapSensitive(paramArgs)
+	driverArgs = dialectArgs(s.dialect, driverArgs)
+	if len(s.hooks) == 0 {
+		rows, err := s.query(ctx, q, _query, driverArgs)
+		return rows, func(error) {}, err
+	}
+	ctx, event := s.beforeQuery(ctx, _query, eventArgs)
+	rows, err := s.query(ctx, q, _query, driverArgs)
+	if err != nil {
+		s.afterQuery(ctx, event, err)
+		return nil, nil, err
+	}
+	return rows, func(err error) {
+		s.afterQuery(ctx, event, err)
+	}, nil
+}
+
+// unmarshalRows scans rows into dest and returns the number of scanned rows. dest is a pointer to a slice of
+// rows or to a single row, which is either a struct, a map[string]any of all columns or a scalar value of the
+// single column. Other types must implement RowMapper. If strict is set, columns without matching struct field
+// are an error.
+func unmarshalRows(rows *sql.Rows, dest any, strict bool) (int, error) {
 	t := reflectx.TypeOf(dest, true)
-	if t.Kind() == reflect.Slice {
-		if reflect.TypeOf(dest).Kind() != reflect.Ptr {
-			return errors.New("dest is no pointer to slice")
-		}
-		elemType := reflectx.ElemTypeOf(dest, true)
-		if elemType.Kind() != reflect.Struct {
-			return errors.New("dest is no slice of structs")
+	isSlice := isRowSlice(t)
+	var mapper RowMapper
+	if !isSlice && t.Kind() != reflect.Struct {
+		mapper, _ = dest.(RowMapper)
+	}
+	if mapper == nil && reflect.TypeOf(dest).Kind() != reflect.Ptr {
+		if isSlice {
+			return 0, errors.New("dest is no pointer to slice")
 		}
+		return 0, errors.New("unable to scan rows for type " + reflect.TypeOf(dest).String())
 	}
+	rowType := t
+	if isSlice {
+		rowType = reflectx.DeRef(t.Elem())
+	}
+	kind := rowKindOf(rowType)
 	cols, err := rows.Columns()
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if mapper == nil && kind == scalarRow && len(cols) != 1 {
+		return 0, errors.New("scanning into " + rowType.String() + " requires a single column, got " + strconv.Itoa(len(cols)))
+	}
+	var fields []fieldPlan
+	if kind == structRow {
+		fields = mappingOf(rowType).fieldPlans(cols)
+		if strict {
+			if err := checkUnmappedColumns(rowType, cols, fields); err != nil {
+				return 0, err
+			}
+		}
 	}
 	columns := make([]any, len(cols))
 	columnPointers := make([]any, len(cols))
 	for i := range columns {
 		columnPointers[i] = &columns[i]
 	}
+	count := 0
 	for rows.Next() {
 		if err := rows.Scan(columnPointers...); err != nil {
-			return err
+			return count, err
 		}
-		switch t.Kind() {
-		case reflect.Struct:
-			if err := scanStruct(cols, columnPointers, dest); err != nil {
-				return err
+		count++
+		switch {
+		case mapper != nil:
+			m := make(map[string]any)
+			for i, colName := range cols {
+				m[colName] = *columnPointers[i].(*any)
 			}
-			return nil
-		case reflect.Slice:
-			elemType := reflectx.ElemTypeOf(dest, true)
-			elem := reflect.New(elemType)
-			if err := scanStruct(cols, columnPointers, elem.Interface()); err != nil {
-				return err
+			if err := mapper.MapRow(m); err != nil {
+				return count, err
+			}
+		case isSlice:
+			elem := reflect.New(rowType)
+			if err := scanRow(kind, elem.Elem(), cols, fields, columnPointers); err != nil {
+				return count, err
 			}
 			v := reflect.ValueOf(dest).Elem()
+			if v.Type().Elem().Kind() != reflect.Ptr {
+				elem = elem.Elem()
+			}
 			v.Set(reflect.Append(v, elem))
+		case kind == scalarRow:
+			// pointers are set by setColumnValue, e.g. to nil for NULL
+			return count, scanRow(kind, reflect.ValueOf(dest).Elem(), cols, fields, columnPointers)
 		default:
-			if mapper, ok := reflect.ValueOf(dest).Interface().(RowMapper); ok {
-				m := make(map[string]any)
-				for i, colName := range cols {
-					m[colName] = *columnPointers[i].(*any)
-				}
-				if err := mapper.MapRow(m); err != nil {
-					return err
-				}
-			} else {
-				return errors.New("unable to scan rows for type " + reflect.TypeOf(dest).String())
-			}
-			continue
+			return count, scanRow(kind, reflectx.DeRefValue(reflect.ValueOf(dest)), cols, fields, columnPointers)
 		}
 	}
-	return nil
+	return count, nil
 }
 
-func scanStruct(columnNames []string, columnPointers []any, dest any) error {
-	for i, colName := range columnNames {
-		val := columnPointers[i].(*any)
-		if *val == nil {
-			continue
-		}
-		currentField := reflect.ValueOf(dest)
-		fieldPath := strings.Split(colName, ".")
-		for i := 0; i < len(fieldPath); i++ {
-			field := reflectx.FindFieldFunc(currentField.Interface(), func(field reflect.StructField) bool {
-				dbTag := reflectx.Tag(field, "db")
-				if dbTag.Value != "" {
-					if dbTag.Value == fieldPath[i] {
-						return true
-					}
-					if len(dbTag.Opts) > 0 && dbTag.Opts[0] == fieldPath[i] {
-						return true
-					}
-				}
-				return strings.EqualFold(field.Name, stringx.ToCamelCase(fieldPath[i]))
-			})
+// isRowSlice reports whether t is a slice of rows rather than a column value like []byte or Array.
+func isRowSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && !reflect.PointerTo(t).Implements(scannerType)
+}
 
-			if !field.IsValid() {
-				continue
-			}
-			if i == len(fieldPath)-1 {
-				pField := field
-				if pField.Kind() != reflect.Ptr {
-					pField = field.Addr()
-				}
-				if scanner, ok := pField.Interface().(sql.Scanner); ok {
-					if err := scanner.Scan(*val); err != nil {
-						return err
-					}
-				} else {
-					if err := reflectx.SetFieldValue(field, *val); err != nil {
-						return err
-					}
-				}
-			} else {
-				if field.Type().Kind() == reflect.Ptr && field.IsNil() {
-					field.Set(reflect.New(field.Type().Elem()))
-				}
-			}
-			currentField = field
+func Exec(q sqlQueryer, query string, args ...any) (sql.Result, error) {
+	return ExecContext(context.Background(), q, query, args...)
+}
 
-		}
-	}
-	return nil
+// ExecContext executes query. Named parameters are compiled for the dialect of q, SQLite if q does not provide one.
+func ExecContext(ctx context.Context, q sqlQueryer, query string, args ...any) (sql.Result, error) {
+	return execContext(ctx, q, sessionOf(q), query, args...)
 }
 
-func Exec(q sqlQueryer, query string, args ...any) (sql.Result, error) {
-	_query, names, err := compileNamedQuery([]byte(query), '?')
+func execContext(ctx context.Context, q sqlQueryer, s session, query string, args ...any) (sql.Result, error) {
+	_query, paramArgs, err := s.bind(query, args)
 	if err != nil {
 		return nil, err
 	}
-	if len(args) == 0 {
-		return q.Exec(_query)
+	driverArgs, eventArgs := unwrapSensitive(paramArgs)
+	driverArgs = dialectArgs(s.dialect, driverArgs)
+	if len(s.hooks) == 0 {
+		return s.exec(ctx, q, _query, driverArgs)
 	}
-	paramArgs, err := extractParamArgs(args[0], names)
-	if err != nil {
-		return nil, err
+	ctx, event := s.beforeQuery(ctx, _query, eventArgs)
+	result, err := s.exec(ctx, q, _query, driverArgs)
+	if err == nil {
+		if n, rowsErr := result.RowsAffected(); rowsErr == nil {
+			event.RowsAffected = n
+		}
 	}
-	return q.Exec(_query, paramArgs...)
+	s.afterQuery(ctx, event, err)
+	return result, err
 }
 
 func extractParamArgs(obj any, names []string) (paramArgs []any, err error) {
@@ -227,33 +289,15 @@ func extractParamArgsFromMap(m map[string]any, names []string) ([]any, error) {
 }
 
 func extractParamArgsFromStruct(obj any, names []string) ([]any, error) {
+	v := reflectx.DeRefValue(reflect.ValueOf(obj))
+	mapping := mappingOf(v.Type())
 	paramArgs := make([]any, 0, len(names))
 	for _, name := range names {
-		nameParts := strings.Split(name, ".")
-		currObj := obj
-		for _, namePart := range nameParts {
-			field := reflectx.FindFieldFunc(currObj, func(field reflect.StructField) bool {
-				dbTag := reflectx.Tag(field, "db")
-				if dbTag.Value != "" {
-					if dbTag.Value == name {
-						return true
-					}
-					if len(dbTag.Opts) > 0 && dbTag.Opts[0] == namePart {
-						return true
-					}
-				}
-				return strings.EqualFold(field.Name, stringx.ToCamelCase(namePart))
-			})
-			if !field.IsValid() {
-				continue
-			}
-			if field.Kind() == reflect.Ptr && field.IsNil() {
-				currObj = nil
-				break
-			}
-			currObj = field.Interface()
+		field := mapping.field(name)
+		if field.index == nil {
+			continue
 		}
-		paramArgs = append(paramArgs, currObj)
+		paramArgs = append(paramArgs, field.argValue(v))
 	}
 	return paramArgs, nil
 }
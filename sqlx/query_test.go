@@ -75,6 +75,60 @@ func TestExec(t *testing.T) {
 				).
 				WillReturnResult(sqlmock.NewResult(8, 2))
 		},
+	}, {
+		name: "map arg slice expansion",
+		args: args{
+			query: "DELETE FROM tab WHERE id IN (:ids) AND name = :name",
+			args:  map[string]any{"ids": []uint{7, 8}, "name": "x"},
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("DELETE FROM tab WHERE id IN (?, ?) AND name = ?").
+				WithArgs(uint(7), uint(8), "x").
+				WillReturnResult(sqlmock.NewResult(0, 2))
+		},
+	}, {
+		name: "object arg slice expansion",
+		args: args{
+			query: "DELETE FROM tab WHERE id IN (:ids)",
+			args:  &struct{ IDs [2]int }{IDs: [2]int{7, 8}},
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("DELETE FROM tab WHERE id IN (?, ?)").
+				WithArgs(7, 8).
+				WillReturnResult(sqlmock.NewResult(0, 2))
+		},
+	}, {
+		name: "empty slice",
+		args: args{
+			query: "DELETE FROM tab WHERE id IN (:ids)",
+			args:  map[string]any{"ids": []int{}},
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("DELETE FROM tab WHERE id IN (NULL)").
+				WillReturnResult(sqlmock.NewResult(0, 0))
+		},
+	}, {
+		name: "bytes are not expanded",
+		args: args{
+			query: "UPDATE tab SET data = :data",
+			args:  map[string]any{"data": []byte("abc")},
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("UPDATE tab SET data = ?").
+				WithArgs([]byte("abc")).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		},
+	}, {
+		name: "slice args with slice values",
+		args: args{
+			query: "DELETE FROM tab WHERE id IN (:ids[0]) OR id IN (:ids[1])",
+			args:  []map[string]any{{"ids": []int{1, 2}}, {"ids": []int{3}}},
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("DELETE FROM tab WHERE id IN (?, ?) OR id IN (?)").
+				WithArgs(1, 2, 3).
+				WillReturnResult(sqlmock.NewResult(0, 3))
+		},
 	}, {
 		name: "slice args with one element",
 		args: args{
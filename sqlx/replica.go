@@ -0,0 +1,172 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WithMaxOpenConns limits the open connections of the primary and each replica, see sql.DB.SetMaxOpenConns.
+func WithMaxOpenConns(n int) Option {
+	return withPool(func(db *sql.DB) { db.SetMaxOpenConns(n) })
+}
+
+// WithMaxIdleConns limits the idle connections of the primary and each replica, see sql.DB.SetMaxIdleConns.
+func WithMaxIdleConns(n int) Option {
+	return withPool(func(db *sql.DB) { db.SetMaxIdleConns(n) })
+}
+
+// WithConnMaxLifetime limits the time connections are reused, see sql.DB.SetConnMaxLifetime.
+func WithConnMaxLifetime(d time.Duration) Option {
+	return withPool(func(db *sql.DB) { db.SetConnMaxLifetime(d) })
+}
+
+// WithConnMaxIdleTime limits the time connections are idle, see sql.DB.SetConnMaxIdleTime.
+func WithConnMaxIdleTime(d time.Duration) Option {
+	return withPool(func(db *sql.DB) { db.SetConnMaxIdleTime(d) })
+}
+
+func withPool(configure func(db *sql.DB)) Option {
+	return func(db *DB) {
+		db.pool = append(db.pool, configure)
+	}
+}
+
+// WithReplicas adds read replicas. Select, Get and QueryContext of the DB are routed to the replicas in turn,
+// unless the context is marked by ForcePrimary. Exec and transactions always use the primary.
+func WithReplicas(replicas ...*sql.DB) Option {
+	return func(db *DB) {
+		for _, r := range replicas {
+			db.replicas.add(r)
+		}
+	}
+}
+
+// WithReplicaDSNs adds read replicas opened by Open with its driver, see WithReplicas. NewDB ignores them.
+func WithReplicaDSNs(dataSourceNames ...string) Option {
+	return func(db *DB) {
+		db.replicaDSNs = append(db.replicaDSNs, dataSourceNames...)
+	}
+}
+
+// WithReplicaHealthCheck pings the replicas every interval. Replicas failing the ping are skipped until they
+// respond again, if none is healthy the primary is used.
+func WithReplicaHealthCheck(interval time.Duration) Option {
+	return func(db *DB) {
+		db.replicas.interval = interval
+	}
+}
+
+type forcePrimaryKey struct{}
+
+// ForcePrimary marks ctx to route reads to the primary, e.g. to read the own writes without replication lag.
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forcePrimaryKey{}, true)
+}
+
+func isPrimaryForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(forcePrimaryKey{}).(bool)
+	return forced
+}
+
+type replica struct {
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// replicaSet selects the healthy replicas round-robin. A nil replicaSet has no replicas, e.g. the one of a DB
+// created as struct literal.
+type replicaSet struct {
+	replicas []*replica
+	next     atomic.Uint64
+	interval time.Duration
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+func (s *replicaSet) add(db *sql.DB) {
+	r := &replica{db: db}
+	r.healthy.Store(true)
+	s.replicas = append(s.replicas, r)
+}
+
+// dbs returns the databases of the replicas.
+func (s *replicaSet) dbs() []*sql.DB {
+	if s == nil {
+		return nil
+	}
+	dbs := make([]*sql.DB, len(s.replicas))
+	for i, r := range s.replicas {
+		dbs[i] = r.db
+	}
+	return dbs
+}
+
+// pick returns the next healthy replica, nil if there is none.
+func (s *replicaSet) pick() *sql.DB {
+	if s == nil {
+		return nil
+	}
+	n := uint64(len(s.replicas))
+	if n == 0 {
+		return nil
+	}
+	start := s.next.Add(1) - 1
+	for i := uint64(0); i < n; i++ {
+		if r := s.replicas[(start+i)%n]; r.healthy.Load() {
+			return r.db
+		}
+	}
+	return nil
+}
+
+// start runs the health checks if an interval is configured.
+func (s *replicaSet) start() {
+	if s == nil || s.interval <= 0 || len(s.replicas) == 0 {
+		return
+	}
+	s.stop = make(chan struct{})
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				s.checkHealth(context.Background())
+			}
+		}
+	}()
+}
+
+func (s *replicaSet) checkHealth(ctx context.Context) {
+	for _, r := range s.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, max(s.interval, time.Second))
+		r.healthy.Store(r.db.PingContext(pingCtx) == nil)
+		cancel()
+	}
+}
+
+// close stops the health checks and closes the replicas.
+func (s *replicaSet) close() error {
+	if s == nil {
+		return nil
+	}
+	if s.stop != nil {
+		close(s.stop)
+		s.done.Wait()
+		s.stop = nil
+	}
+	var errs []error
+	for _, r := range s.replicas {
+		errs = append(errs, r.db.Close())
+	}
+	return errors.Join(errs...)
+}
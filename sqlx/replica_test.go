@@ -0,0 +1,192 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type replicaItem struct {
+	_    struct{} `db:"items,table"`
+	ID   int64    `db:"id,pk,auto"`
+	Name string
+}
+
+func Test_DB_Replicas(t *testing.T) {
+	type mocks struct {
+		primary, replica1, replica2 sqlmock.Sqlmock
+	}
+	tests := []struct {
+		name        string
+		query       func(db *DB) error
+		setupExpect func(m mocks)
+	}{{
+		name: "round-robin reads",
+		query: func(db *DB) error {
+			for range 3 {
+				var got []*testStruct
+				if err := db.Select(&got, "SELECT * FROM tab"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		setupExpect: func(m mocks) {
+			m.replica1.ExpectQuery("SELECT * FROM tab").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			m.replica2.ExpectQuery("SELECT * FROM tab").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			m.replica1.ExpectQuery("SELECT * FROM tab").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		},
+	}, {
+		name: "writes",
+		query: func(db *DB) error {
+			if _, err := db.Exec("DELETE FROM tab"); err != nil {
+				return err
+			}
+			return db.InTx(context.Background(), func(tx *Transaction) error {
+				var got []*testStruct
+				return tx.Select(&got, "SELECT * FROM tab")
+			})
+		},
+		setupExpect: func(m mocks) {
+			m.primary.ExpectExec("DELETE FROM tab").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.primary.ExpectBegin()
+			m.primary.ExpectQuery("SELECT * FROM tab").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			m.primary.ExpectCommit()
+		},
+	}, {
+		name: "force primary",
+		query: func(db *DB) error {
+			_, err := SelectScalar[int](db, "SELECT COUNT(*) FROM tab")
+			if err != nil {
+				return err
+			}
+			return db.GetContext(ForcePrimary(context.Background()), new(int), "SELECT COUNT(*) FROM tab")
+		},
+		setupExpect: func(m mocks) {
+			m.replica1.ExpectQuery("SELECT COUNT(*) FROM tab").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+			m.primary.ExpectQuery("SELECT COUNT(*) FROM tab").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		},
+	}, {
+		name: "create returning",
+		query: func(db *DB) error {
+			repo, err := NewRepository[replicaItem](db)
+			if err != nil {
+				return err
+			}
+			return repo.Create(context.Background(), &replicaItem{Name: "Hans"})
+		},
+		setupExpect: func(m mocks) {
+			m.primary.ExpectQuery("INSERT INTO items (name) VALUES (?) RETURNING id").
+				WithArgs("Hans").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		},
+	}, {
+		name: "unhealthy replica",
+		query: func(db *DB) error {
+			db.replicas.checkHealth(context.Background())
+			for range 2 {
+				var got []*testStruct
+				if err := db.Select(&got, "SELECT * FROM tab"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		setupExpect: func(m mocks) {
+			m.replica1.ExpectPing().WillReturnError(errors.New("down"))
+			m.replica2.ExpectPing()
+			m.replica2.ExpectQuery("SELECT * FROM tab").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			m.replica2.ExpectQuery("SELECT * FROM tab").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		},
+	}, {
+		name: "no healthy replica",
+		query: func(db *DB) error {
+			db.replicas.checkHealth(context.Background())
+			var got []*testStruct
+			return db.Select(&got, "SELECT * FROM tab")
+		},
+		setupExpect: func(m mocks) {
+			m.replica1.ExpectPing().WillReturnError(errors.New("down"))
+			m.replica2.ExpectPing().WillReturnError(errors.New("down"))
+			m.primary.ExpectQuery("SELECT * FROM tab").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m mocks
+			var dbs [3]*sql.DB
+			for i, mock := range []*sqlmock.Sqlmock{&m.primary, &m.replica1, &m.replica2} {
+				var err error
+				dbs[i], *mock, err = sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual), sqlmock.MonitorPingsOption(true))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			tt.setupExpect(m)
+			db := NewDB(dbs[0], WithReplicas(dbs[1], dbs[2]))
+			if err := tt.query(db); err != nil {
+				t.Fatalf("query error = %v", err)
+			}
+			for _, mock := range []sqlmock.Sqlmock{m.primary, m.replica1, m.replica2} {
+				if err := mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestOpen_Pool(t *testing.T) {
+	db, err := Open("sqlite3", ":memory:",
+		WithReplicaDSNs(":memory:"),
+		WithMaxOpenConns(3),
+		WithConnMaxLifetime(time.Minute),
+		WithReplicaHealthCheck(time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := db.DB.Stats().MaxOpenConnections; got != 3 {
+		t.Errorf("primary MaxOpenConnections = %d, want 3", got)
+	}
+	if got := len(db.replicas.replicas); got != 1 {
+		t.Fatalf("replicas = %d, want 1", got)
+	}
+	if got := db.replicas.replicas[0].db.Stats().MaxOpenConnections; got != 3 {
+		t.Errorf("replica MaxOpenConnections = %d, want 3", got)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := db.replicas.replicas[0].db.Ping(); err == nil {
+		t.Error("replica not closed")
+	}
+}
+
+func Test_DB_StructLiteral(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery("SELECT * FROM tab").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectClose()
+	db := &DB{DB: sqlDB}
+	var got []*testStruct
+	if err := db.Select(&got, "SELECT * FROM tab"); err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if len(got) != 1 {
+		t.Errorf("Select() = %v, want 1 row", got)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
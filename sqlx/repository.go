@@ -0,0 +1,339 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/vloryan/go-libs/reflectx"
+	"github.com/vloryan/go-libs/sqlx/filter"
+	"github.com/vloryan/go-libs/sqlx/pagination"
+	"github.com/vloryan/go-libs/stringx"
+)
+
+// ErrOptimisticLock is returned by Repository.Update and Repository.Delete if the version of the entity
+// does not match the stored one.
+var ErrOptimisticLock = errors.New("optimistic lock failed")
+
+// Repository provides the basic operations on the table of the struct type T. It is configured by the db tags
+// of T:
+//   - the table is set by a blank field, e.g. _ struct{} with tag db:"people,table", defaulting to the snake cased type name
+//   - the "pk" option marks the primary key, defaulting to the column id
+//   - the "auto" option marks columns generated by the database on insert, which are read back after Create
+//   - the "readonly" option marks columns which are neither inserted nor updated
+//   - the "version" option marks an integer column used for optimistic locking
+//   - the "tenant", "deleted", "created" and "updated" options mark the columns of Scope
+//   - "-" ignores the field
+type Repository[T any] struct {
+	q       NamedQuerier
+	table   string
+	columns []structColumn
+	pk      structColumn
+	version *structColumn
+}
+
+// NewRepository creates a repository for T using q, which can be exchanged by With.
+func NewRepository[T any](q NamedQuerier) (*Repository[T], error) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, errors.New("repository type '" + t.String() + "' is no struct")
+	}
+	r := &Repository[T]{q: q, table: tableName(t), columns: mappingOf(t).structColumns()}
+	if !identifierRegex.MatchString(r.table) {
+		return nil, errors.New("invalid table '" + r.table + "'")
+	}
+	var pks []structColumn
+	for i, c := range r.columns {
+		if c.has("pk") {
+			pks = append(pks, c)
+		}
+		if c.has("version") {
+			switch t.FieldByIndex(c.index).Type.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			default:
+				return nil, errors.New("version column '" + c.name + "' is no integer")
+			}
+			r.version = &r.columns[i]
+		}
+	}
+	if len(pks) == 0 {
+		for _, c := range r.columns {
+			if c.name == "id" {
+				pks = append(pks, c)
+			}
+		}
+	}
+	switch len(pks) {
+	case 0:
+		return nil, errors.New("no primary key found for type '" + t.String() + "'")
+	case 1:
+		r.pk = pks[0]
+	default:
+		return nil, errors.New("composite primary keys are not supported")
+	}
+	return r, nil
+}
+
+// tableName returns the table set by the tag of a blank field of t, or the snake cased name of t.
+func tableName(t reflect.Type) string {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if dbTag := reflectx.Tag(field, "db"); field.Name == "_" && dbTag.Has("table") {
+			return dbTag.Value
+		}
+	}
+	return stringx.ToSnakeCase(t.Name())
+}
+
+// With returns a copy of the repository using q, e.g. to operate within a transaction.
+func (r *Repository[T]) With(q NamedQuerier) *Repository[T] {
+	c := *r
+	c.q = q
+	return &c
+}
+
+func (r *Repository[T]) Table() string {
+	return r.table
+}
+
+// FindByID selects the entity with the primary key id. sql.ErrNoRows is returned if there is none.
+func (r *Repository[T]) FindByID(ctx context.Context, id any) (*T, error) {
+	entity := new(T)
+	args := map[string]any{"id": id}
+	query := "SELECT * FROM " + r.table + " WHERE " + r.pk.name + " = :id"
+	cond, err := r.scope().condition(r.q.Dialect(), r.columns, args)
+	if err != nil {
+		return nil, err
+	}
+	if cond != "" {
+		query += " AND " + cond
+	}
+	if err := r.q.GetContext(ctx, entity, query, args); err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+// List selects the entities matching criteria within page. The field paths of criteria refer to columns,
+// a nil criteria or page selects all within the scope.
+func (r *Repository[T]) List(ctx context.Context, criteria filter.Criteria, page *pagination.Page) ([]*T, error) {
+	if scope := r.scope().criteria(r.columns); isEmptyCriteria(criteria) {
+		criteria = scope
+	} else if !isEmptyCriteria(scope) {
+		criteria = criteria.And(scope)
+	}
+	cond, args, err := CompileCriteria(criteria, r.q.Dialect())
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT * FROM " + r.table
+	var queryArgs []any
+	if cond != "" {
+		query += " WHERE " + cond
+		queryArgs = append(queryArgs, args)
+	}
+	entities := make([]*T, 0)
+	if err := SelectPageContext(ctx, r.q, &entities, query, page, queryArgs...); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Create inserts entity. Generated columns are read back into entity, a version column starts at 1. The tenant
+// and the audit timestamps are set unless they are set already.
+func (r *Repository[T]) Create(ctx context.Context, entity *T) error {
+	v := reflect.ValueOf(entity).Elem()
+	if err := r.scope().prepareInsert(v, r.columns, time.Now()); err != nil {
+		return err
+	}
+	if r.version != nil {
+		if f, err := fieldByIndexAlloc(v, r.version.index); err == nil && f.IsZero() {
+			if err := reflectx.SetFieldValue(f, 1); err != nil {
+				return err
+			}
+		}
+	}
+	var names, params, generated []string
+	args := make(map[string]any)
+	for _, c := range r.columns {
+		if c.has("auto") {
+			generated = append(generated, c.name)
+			continue
+		}
+		if c.has("readonly") {
+			continue
+		}
+		names = append(names, c.name)
+		params = append(params, ":"+c.name)
+		args[c.name] = c.argValue(v)
+	}
+	dialect := r.q.Dialect()
+	// the generated columns are selected by the INSERT, which must not be routed to a replica
+	ctx = ForcePrimary(ctx)
+	query := "INSERT INTO " + r.table + " (" + strings.Join(names, ", ") + ")"
+	values := " VALUES (" + strings.Join(params, ", ") + ")"
+	if len(generated) == 0 {
+		_, err := r.q.ExecContext(ctx, query+values, args)
+		return err
+	}
+	switch dialect {
+	case MySQL:
+		result, err := r.q.ExecContext(ctx, query+values, args)
+		if err != nil {
+			return err
+		}
+		if !r.pk.has("auto") {
+			return nil
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		pk, err := fieldByIndexAlloc(v, r.pk.index)
+		if err != nil {
+			return err
+		}
+		return reflectx.SetFieldValue(pk, id)
+	case SQLServer:
+		output := make([]string, len(generated))
+		for i, name := range generated {
+			output[i] = "INSERTED." + name
+		}
+		return r.q.SelectContext(ctx, entity, query+" OUTPUT "+strings.Join(output, ", ")+values, args)
+	default:
+		return r.q.SelectContext(ctx, entity, query+values+" RETURNING "+strings.Join(generated, ", "), args)
+	}
+}
+
+// Update updates the columns of entity. If T has a version column the update requires the stored version
+// to match and increments it, ErrOptimisticLock is returned otherwise. sql.ErrNoRows is returned if the entity
+// does not exist. The tenant, the creation time and the soft delete time are not updated.
+func (r *Repository[T]) Update(ctx context.Context, entity *T) error {
+	v := reflect.ValueOf(entity).Elem()
+	var sets []string
+	args := make(map[string]any)
+	now := time.Now()
+	for _, c := range r.columns {
+		if c.has("auto") || c.has("readonly") || c.has("pk") || c.name == r.pk.name || c.has("version") ||
+			c.has("tenant") || c.has("created") || c.has("deleted") {
+			continue
+		}
+		if c.has("updated") {
+			if err := setColumnTime(v, c, now); err != nil {
+				return err
+			}
+		}
+		sets = append(sets, c.name+" = :"+c.name)
+		args[c.name] = c.argValue(v)
+	}
+	if r.version != nil {
+		sets = append(sets, r.version.name+" = "+r.version.name+" + 1")
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+	where, err := r.whereEntity(v, args)
+	if err != nil {
+		return err
+	}
+	query := "UPDATE " + r.table + " SET " + strings.Join(sets, ", ") + where
+	if err := r.execAffecting(ctx, query, where, args); err != nil {
+		return err
+	}
+	if r.version != nil {
+		f, err := fieldByIndexAlloc(v, r.version.index)
+		if err != nil {
+			return err
+		}
+		if f.CanInt() {
+			f.SetInt(f.Int() + 1)
+		} else {
+			f.SetUint(f.Uint() + 1)
+		}
+	}
+	return nil
+}
+
+// Delete deletes entity, checking its version like Update. If T has a "deleted" column the entity is soft
+// deleted by setting it.
+func (r *Repository[T]) Delete(ctx context.Context, entity *T) error {
+	v := reflect.ValueOf(entity).Elem()
+	args := make(map[string]any)
+	where, err := r.whereEntity(v, args)
+	if err != nil {
+		return err
+	}
+	deleted, ok := r.column("deleted")
+	if !ok {
+		return r.execAffecting(ctx, "DELETE FROM "+r.table+where, where, args)
+	}
+	now := time.Now()
+	args["deleted_at"] = now
+	if err := r.execAffecting(ctx, "UPDATE "+r.table+" SET "+deleted.name+" = :deleted_at"+where, where, args); err != nil {
+		return err
+	}
+	return setColumnTime(v, deleted, now)
+}
+
+// whereEntity renders the condition identifying the entity v by its primary key and version within the scope.
+func (r *Repository[T]) whereEntity(v reflect.Value, args map[string]any) (string, error) {
+	args["where_pk"] = fieldValueByIndex(v, r.pk.index)
+	where := " WHERE " + r.pk.name + " = :where_pk"
+	if r.version != nil {
+		args["where_version"] = fieldValueByIndex(v, r.version.index)
+		where += " AND " + r.version.name + " = :where_version"
+	}
+	cond, err := r.scope().condition(r.q.Dialect(), r.columns, args)
+	if err != nil {
+		return "", err
+	}
+	if cond != "" {
+		where += " AND " + cond
+	}
+	return where, nil
+}
+
+func (r *Repository[T]) scope() Scope {
+	return sessionOf(r.q).scope
+}
+
+// column returns the first column with the tag option opt.
+func (r *Repository[T]) column(opt string) (structColumn, bool) {
+	for _, c := range r.columns {
+		if c.has(opt) {
+			return c, true
+		}
+	}
+	return structColumn{}, false
+}
+
+// execAffecting executes query, which must affect a row matching where. MySQL counts changed rows only, so
+// without version column a row left unchanged is looked up to tell it from a missing one.
+func (r *Repository[T]) execAffecting(ctx context.Context, query, where string, args map[string]any) error {
+	result, err := r.q.ExecContext(ctx, query, args)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	if r.version != nil {
+		return ErrOptimisticLock
+	}
+	count, err := SelectScalarContext[int](ForcePrimary(ctx), r.q, "SELECT COUNT(*) FROM "+r.table+where, args)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return sql.ErrNoRows
+}
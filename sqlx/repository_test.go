@@ -0,0 +1,183 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/vloryan/go-libs/sqlx/filter"
+	"github.com/vloryan/go-libs/sqlx/pagination"
+)
+
+type repoPerson struct {
+	_       struct{} `db:"people,table"`
+	ID      int64    `db:"id,pk,auto"`
+	Name    string
+	Age     int
+	Created string `db:"created,readonly"`
+	Version int    `db:"version,version"`
+}
+
+func prepareRepository(t *testing.T) (*DB, *Repository[repoPerson]) {
+	db, err := Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE TABLE people (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL,
+				age INTEGER NOT NULL,
+				created TEXT NOT NULL DEFAULT 'today',
+				version INTEGER NOT NULL
+			)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := NewRepository[repoPerson](db)
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	return db, repo
+}
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	_, repo := prepareRepository(t)
+	if got := repo.Table(); got != "people" {
+		t.Fatalf("Table() = %v, want people", got)
+	}
+	for _, p := range []*repoPerson{{Name: "Hans", Age: 40}, {Name: "Anna", Age: 30}, {Name: "Ludger", Age: 50}} {
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	got, err := repo.FindByID(ctx, 2)
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if diff := cmp.Diff(&repoPerson{ID: 2, Name: "Anna", Age: 30, Created: "today", Version: 1}, got); diff != "" {
+		t.Fatalf("FindByID() mismatch (-want +got):\n%s", diff)
+	}
+
+	page := pagination.NewPage(0, 1, "-age")
+	list, err := repo.List(ctx, filter.Field("age").GtEq(40), page)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].Name != "Ludger" || page.TotalCount != 2 {
+		t.Fatalf("List() = %v, TotalCount %d", list, page.TotalCount)
+	}
+
+	stale := *got
+	got.Age = 31
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got.Version != 2 {
+		t.Fatalf("Update() Version = %d, want 2", got.Version)
+	}
+	if err := repo.Update(ctx, &stale); !errors.Is(err, ErrOptimisticLock) {
+		t.Fatalf("Update() error = %v, want %v", err, ErrOptimisticLock)
+	}
+	if err := repo.Delete(ctx, &stale); !errors.Is(err, ErrOptimisticLock) {
+		t.Fatalf("Delete() error = %v, want %v", err, ErrOptimisticLock)
+	}
+	if err := repo.Delete(ctx, got); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.FindByID(ctx, 2); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("FindByID() error = %v, want %v", err, sql.ErrNoRows)
+	}
+}
+
+func TestRepository_With(t *testing.T) {
+	ctx := context.Background()
+	db, repo := prepareRepository(t)
+	errFailed := errors.New("failed")
+	err := db.InTx(ctx, func(tx *Transaction) error {
+		if err := repo.With(tx).Create(ctx, &repoPerson{Name: "Hans"}); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("InTx() error = %v, want %v", err, errFailed)
+	}
+	list, err := repo.List(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 0 {
+		t.Fatalf("List() = %v, want none", list)
+	}
+}
+
+func TestNewRepository(t *testing.T) {
+	type noPK struct {
+		Name string
+	}
+	type compositePK struct {
+		A int `db:"a,pk"`
+		B int `db:"b,pk"`
+	}
+	type invalidVersion struct {
+		ID      int
+		Version string `db:"version,version"`
+	}
+	if _, err := NewRepository[noPK](nil); err == nil {
+		t.Error("NewRepository() expected error for missing primary key")
+	}
+	if _, err := NewRepository[compositePK](nil); err == nil {
+		t.Error("NewRepository() expected error for composite primary key")
+	}
+	if _, err := NewRepository[invalidVersion](nil); err == nil {
+		t.Error("NewRepository() expected error for invalid version column")
+	}
+	repo, err := NewRepository[testStruct](nil)
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	if got := repo.Table(); got != "test_struct" {
+		t.Errorf("Table() = %v, want test_struct", got)
+	}
+}
+
+func TestRepository_Update_Unchanged(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		wantErr error
+	}{
+		{name: "unchanged", count: 1},
+		{name: "missing", count: 0, wantErr: sql.ErrNoRows},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// MySQL reports rows left unchanged as not affected
+			mock.ExpectExec("UPDATE items SET name = ? WHERE id = ?").
+				WithArgs("Hans", int64(1)).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT COUNT(*) FROM items WHERE id = ?").
+				WithArgs(int64(1)).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.count))
+			repo, err := NewRepository[replicaItem](NewDB(sqlDB, WithDialect(MySQL)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.Update(context.Background(), &replicaItem{ID: 1, Name: "Hans"}); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package sqlx
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how managed transactions are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, values less than 1 are treated as 1.
+	MaxAttempts int
+	// Backoff returns the delay before the given retry attempt. No delay if nil.
+	Backoff func(attempt int) time.Duration
+	// Retryable decides if a failed attempt is retried. Defaults to IsSerializationFailure.
+	Retryable func(err error) bool
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff: func(attempt int) time.Duration {
+		return time.Duration(1<<attempt) * 10 * time.Millisecond
+	},
+	Retryable: IsSerializationFailure,
+}
+
+func (p RetryPolicy) attempts() int {
+	return max(p.MaxAttempts, 1)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsSerializationFailure(err)
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.Backoff == nil {
+		return 0
+	}
+	return p.Backoff(attempt)
+}
+
+var serializationFailureMessages = []string{
+	"could not serialize access",             // postgres
+	"deadlock detected",                      // postgres
+	"Deadlock found when trying to get lock", // mysql
+	"Lock wait timeout exceeded",             // mysql
+	"database is locked",                     // sqlite
+	"database table is locked",               // sqlite
+}
+
+// IsSerializationFailure reports whether err is caused by a serialization failure or deadlock, after which
+// a transaction can be retried.
+func IsSerializationFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		state := stateErr.SQLState()
+		return state == "40001" || state == "40P01"
+	}
+	msg := err.Error()
+	for _, m := range serializationFailureMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package sqlx
+
+import (
+	"errors"
+	"maps"
+	"reflect"
+	"time"
+
+	"github.com/vloryan/go-libs/sqlx/filter"
+)
+
+// Scope restricts the statements built by Repository and InsertMany to the rows of a tenant which are not soft
+// deleted. The columns are marked by db tag options:
+//   - "tenant" marks the tenant column, which is set to Tenant on insert and can not be updated
+//   - "deleted" marks the nullable timestamp of soft deletes, Repository.Delete sets it instead of deleting the row
+//   - "created" and "updated" mark timestamps set on insert, the latter on update as well
+//
+// Audit timestamps are set regardless of the scope.
+type Scope struct {
+	// Tenant restricts the rows to those of the tenant, nil disables tenant scoping.
+	Tenant any
+	// IncludeDeleted includes soft deleted rows.
+	IncludeDeleted bool
+}
+
+// Scoped returns a copy of the DB restricted by scope, which is inherited by its transactions.
+func (db *DB) Scoped(scope Scope) *DB {
+	c := *db
+	c.scope = scope
+	return &c
+}
+
+// Scoped returns a copy of the transaction restricted by scope.
+func (t Transaction) Scoped(scope Scope) *Transaction {
+	t.scope = scope
+	return &t
+}
+
+// criteria returns the conditions of the scope on columns.
+func (s Scope) criteria(columns []structColumn) filter.Criteria {
+	criteria := filter.New()
+	for _, c := range columns {
+		switch {
+		case c.has("tenant") && s.Tenant != nil:
+			criteria = criteria.And(filter.Field(c.name).Eq(s.Tenant))
+		case c.has("deleted") && !s.IncludeDeleted:
+			criteria = criteria.And(filter.Field(c.name).IsNil())
+		}
+	}
+	return criteria
+}
+
+// condition renders the conditions of the scope on columns, adding the named args they reference to args.
+// An unrestricted scope results in an empty condition.
+func (s Scope) condition(dialect Dialect, columns []structColumn, args map[string]any) (string, error) {
+	compiler := &CriteriaCompiler{Dialect: dialect, ParamPrefix: "scope"}
+	cond, scopeArgs, err := compiler.Compile(s.criteria(columns))
+	if err != nil {
+		return "", err
+	}
+	maps.Copy(args, scopeArgs)
+	return cond, nil
+}
+
+// prepareInsert sets the tenant and the audit timestamps of row unless they are set.
+func (s Scope) prepareInsert(row reflect.Value, columns []structColumn, now time.Time) error {
+	for _, c := range columns {
+		var value any
+		switch {
+		case c.has("tenant") && s.Tenant != nil:
+			value = s.Tenant
+		case c.has("created") || c.has("updated"):
+			value = now
+		default:
+			continue
+		}
+		f, err := fieldByIndexAlloc(row, c.index)
+		if err != nil {
+			return err
+		}
+		if !f.IsZero() {
+			if c.has("tenant") && !equalsValue(reflect.Indirect(f), s.Tenant) {
+				return errors.New("tenant of row does not match the scope")
+			}
+			continue
+		}
+		if err := setColumnValue(f, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// equalsValue reports whether v equals value converted to the type of v.
+func equalsValue(v reflect.Value, value any) bool {
+	rv := reflect.ValueOf(value)
+	if !rv.Type().ConvertibleTo(v.Type()) {
+		return false
+	}
+	return reflect.DeepEqual(rv.Convert(v.Type()).Interface(), v.Interface())
+}
+
+// setColumnTime sets the field of the column c in row to now.
+func setColumnTime(row reflect.Value, c structColumn, now time.Time) error {
+	f, err := fieldByIndexAlloc(row, c.index)
+	if err != nil {
+		return err
+	}
+	return setColumnValue(f, now)
+}
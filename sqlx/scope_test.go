@@ -0,0 +1,189 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/vloryan/go-libs/sqlx/filter"
+)
+
+type scopedNote struct {
+	_         struct{}   `db:"notes,table"`
+	ID        int64      `db:"id,pk,auto"`
+	TenantID  int64      `db:"tenant_id,tenant"`
+	Text      string     `db:"text"`
+	CreatedAt time.Time  `db:"created_at,created"`
+	UpdatedAt time.Time  `db:"updated_at,updated"`
+	DeletedAt *time.Time `db:"deleted_at,deleted"`
+}
+
+func TestScope_condition(t *testing.T) {
+	columns := mappingOf(reflect.TypeFor[scopedNote]()).structColumns()
+	tests := []struct {
+		name     string
+		scope    Scope
+		want     string
+		wantArgs map[string]any
+	}{
+		{name: "default", want: "deleted_at IS NULL", wantArgs: map[string]any{}},
+		{name: "tenant", scope: Scope{Tenant: 7}, want: "(tenant_id = :scope1 AND deleted_at IS NULL)", wantArgs: map[string]any{"scope1": 7}},
+		{name: "include deleted", scope: Scope{IncludeDeleted: true}, want: "", wantArgs: map[string]any{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := make(map[string]any)
+			got, err := tt.scope.condition(SQLite, columns, args)
+			if err != nil {
+				t.Fatalf("condition() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("condition() = %v, want %v", got, tt.want)
+			}
+			if diff := cmp.Diff(tt.wantArgs, args); diff != "" {
+				t.Errorf("condition() args mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRepository_Scoped(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE TABLE notes (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				tenant_id INTEGER NOT NULL,
+				text TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				deleted_at TIMESTAMP
+			)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := NewRepository[scopedNote](db.Scoped(Scope{Tenant: 1}))
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	other := repo.With(db.Scoped(Scope{Tenant: 2}))
+
+	note := &scopedNote{Text: "first"}
+	if err := repo.Create(ctx, note); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if note.TenantID != 1 || note.CreatedAt.IsZero() || !note.UpdatedAt.Equal(note.CreatedAt) {
+		t.Fatalf("Create() did not set tenant and audit columns: %+v", note)
+	}
+	if err := repo.Create(ctx, &scopedNote{TenantID: 2, Text: "foreign"}); err == nil {
+		t.Fatal("Create() expected error for tenant mismatch")
+	}
+	if _, err := InsertMany(db.Scoped(Scope{Tenant: 2}), "notes", []scopedNote{{Text: "second"}, {Text: "third"}}); err != nil {
+		t.Fatalf("InsertMany() error = %v", err)
+	}
+
+	if _, err := other.FindByID(ctx, note.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("FindByID() of other tenant error = %v, want %v", err, sql.ErrNoRows)
+	}
+	if err := other.Update(ctx, note); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Update() of other tenant error = %v, want %v", err, sql.ErrNoRows)
+	}
+	listed, err := other.List(ctx, filter.Field("text").Like("%d"), nil)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(listed) != 2 || listed[0].TenantID != 2 || listed[0].CreatedAt.IsZero() {
+		t.Fatalf("List() = %+v, want the notes of tenant 2", listed)
+	}
+
+	created := note.CreatedAt
+	time.Sleep(time.Millisecond)
+	note.Text = "changed"
+	if err := repo.Update(ctx, note); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if !note.UpdatedAt.After(created) || !note.CreatedAt.Equal(created) {
+		t.Fatalf("Update() audit columns = %v, %v", note.CreatedAt, note.UpdatedAt)
+	}
+
+	err = db.InTx(ctx, func(tx *Transaction) error {
+		return repo.With(tx.Scoped(Scope{Tenant: 1})).Delete(ctx, note)
+	})
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if note.DeletedAt == nil {
+		t.Fatal("Delete() did not set deleted_at")
+	}
+	if _, err := repo.FindByID(ctx, note.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("FindByID() of deleted error = %v, want %v", err, sql.ErrNoRows)
+	}
+	if err := repo.Delete(ctx, note); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Delete() of deleted error = %v, want %v", err, sql.ErrNoRows)
+	}
+	got, err := repo.With(db.Scoped(Scope{Tenant: 1, IncludeDeleted: true})).FindByID(ctx, note.ID)
+	if err != nil {
+		t.Fatalf("FindByID() including deleted error = %v", err)
+	}
+	if got.Text != "changed" || got.DeletedAt == nil {
+		t.Fatalf("FindByID() = %+v", got)
+	}
+
+	including := repo.With(db.Scoped(Scope{Tenant: 1, IncludeDeleted: true}))
+	stale := *got
+	stale.DeletedAt = nil
+	stale.Text = "stale"
+	if err := including.Update(ctx, &stale); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, err = including.FindByID(ctx, note.ID); err != nil || got.Text != "stale" || got.DeletedAt == nil {
+		t.Fatalf("Update() of stale entity undeleted it: %+v, %v", got, err)
+	}
+}
+
+func TestUpsert_Scoped(t *testing.T) {
+	db, err := Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`CREATE TABLE notes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		tenant_id INTEGER NOT NULL,
+		text TEXT NOT NULL UNIQUE,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		deleted_at TIMESTAMP
+	)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InsertMany(db.Scoped(Scope{Tenant: 1}), "notes", []scopedNote{{Text: "shared"}}); err != nil {
+		t.Fatalf("InsertMany() error = %v", err)
+	}
+	n, err := Upsert(db.Scoped(Scope{Tenant: 2}), "notes", []scopedNote{{Text: "shared"}}, "text")
+	if err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	if n != 0 {
+		t.Errorf("Upsert() = %d, want 0", n)
+	}
+	var got []scopedNote
+	if err := db.Select(&got, "SELECT * FROM notes"); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].TenantID != 1 || !got[0].UpdatedAt.Equal(got[0].CreatedAt) {
+		t.Fatalf("Upsert() of other tenant changed the row: %+v", got)
+	}
+	if n, err := Upsert(db.Scoped(Scope{Tenant: 1}), "notes", []scopedNote{{Text: "shared"}}, "text"); err != nil || n != 1 {
+		t.Fatalf("Upsert() = %d, %v, want 1", n, err)
+	}
+	if _, err := Upsert(NewDB(db.DB, WithDialect(MySQL)).Scoped(Scope{Tenant: 1}), "notes", []scopedNote{{Text: "shared"}}, "text"); err == nil {
+		t.Fatal("Upsert() expected error for scoped MySQL upsert")
+	}
+}
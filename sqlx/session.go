@@ -0,0 +1,80 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+)
+
+// session holds the settings a DB shares with its transactions.
+type session struct {
+	dialect Dialect
+	// strict makes scanning fail on columns without matching field
+	strict bool
+	hooks  []Hook
+	// stmts caches compiled queries and prepared statements, nil if disabled
+	stmts *stmtCache
+	scope Scope
+}
+
+func (s session) Dialect() Dialect {
+	return s.dialect
+}
+
+func (s session) settings() session {
+	return s
+}
+
+// sessionOf returns the settings of q, which default to the dialect of q if it provides one, SQLite otherwise.
+func sessionOf(q any) session {
+	if s, ok := q.(interface{ settings() session }); ok {
+		return s.settings()
+	}
+	if d, ok := q.(interface{ Dialect() Dialect }); ok {
+		return session{dialect: d.Dialect()}
+	}
+	return session{}
+}
+
+// bind compiles query and binds args to its named parameters, see bindNamedQuery. Queries without args are
+// passed as they are.
+func (s session) bind(query string, args []any) (string, []any, error) {
+	if len(args) == 0 {
+		return query, args, nil
+	}
+	if s.stmts == nil {
+		return bindNamedQuery(query, s.dialect, args)
+	}
+	parsed, err := s.stmts.compile(query, s.dialect.backslashEscapes())
+	if err != nil {
+		return "", nil, err
+	}
+	return parsed.bind(s.dialect.paramMarker(), args)
+}
+
+func (s session) query(ctx context.Context, q sqlQueryer, query string, args []any) (*sql.Rows, error) {
+	if s.stmts != nil && len(args) > 0 {
+		stmt, release, err := s.stmts.prepare(ctx, q, query)
+		if err != nil {
+			return nil, err
+		}
+		if stmt != nil {
+			defer release()
+			return stmt.QueryContext(ctx, args...)
+		}
+	}
+	return q.QueryContext(ctx, query, args...)
+}
+
+func (s session) exec(ctx context.Context, q sqlQueryer, query string, args []any) (sql.Result, error) {
+	if s.stmts != nil && len(args) > 0 {
+		stmt, release, err := s.stmts.prepare(ctx, q, query)
+		if err != nil {
+			return nil, err
+		}
+		if stmt != nil {
+			defer release()
+			return stmt.ExecContext(ctx, args...)
+		}
+	}
+	return q.ExecContext(ctx, query, args...)
+}
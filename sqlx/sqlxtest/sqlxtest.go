@@ -0,0 +1,233 @@
+// Package sqlxtest provides in-memory SQLite databases with schema and fixtures for tests.
+package sqlxtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/vloryan/go-libs/sqlx"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	dbCount         atomic.Int64
+)
+
+type config struct {
+	schema    []fileSet
+	fixtures  []fileSet
+	dbOptions []sqlx.Option
+}
+
+type fileSet struct {
+	fsys     fs.FS
+	patterns []string
+}
+
+// Option configures the database created by New.
+type Option func(c *config)
+
+// WithSchema executes the SQL files of fsys matching patterns, "*.sql" if none is given, in the order of their
+// names.
+func WithSchema(fsys fs.FS, patterns ...string) Option {
+	return func(c *config) {
+		c.schema = append(c.schema, fileSet{fsys: fsys, patterns: patterns})
+	}
+}
+
+// WithFixtures loads the fixture files of fsys matching patterns, "*.yaml", "*.yml" and "*.json" if none is
+// given, see LoadFixtures.
+func WithFixtures(fsys fs.FS, patterns ...string) Option {
+	return func(c *config) {
+		c.fixtures = append(c.fixtures, fileSet{fsys: fsys, patterns: patterns})
+	}
+}
+
+// WithDBOptions configures the sqlx.DB.
+func WithDBOptions(opts ...sqlx.Option) Option {
+	return func(c *config) {
+		c.dbOptions = append(c.dbOptions, opts...)
+	}
+}
+
+// New opens an in-memory SQLite database of its own for t, which is closed when t finishes. Foreign keys are
+// enforced.
+func New(t testing.TB, opts ...Option) *sqlx.DB {
+	t.Helper()
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	dsn := fmt.Sprintf("file:sqlxtest_%d?mode=memory&cache=shared&_fk=1", dbCount.Add(1))
+	db, err := sqlx.Open("sqlite3", dsn, c.dbOptions...)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	for _, set := range c.schema {
+		files, err := matchFiles(set.fsys, set.patterns, "*.sql")
+		if err != nil {
+			t.Fatalf("schema: %v", err)
+		}
+		for _, name := range files {
+			data, err := fs.ReadFile(set.fsys, name)
+			if err != nil {
+				t.Fatalf("schema: %v", err)
+			}
+			if _, err := db.Exec(string(data)); err != nil {
+				t.Fatalf("schema %s: %v", name, err)
+			}
+		}
+	}
+	for _, set := range c.fixtures {
+		LoadFixtures(t, db, set.fsys, set.patterns...)
+	}
+	return db
+}
+
+// Tx begins a transaction which is rolled back when t finishes.
+func Tx(t testing.TB, db *sqlx.DB) *sqlx.Transaction {
+	t.Helper()
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("begin transaction: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = tx.Rollback()
+	})
+	return tx
+}
+
+// LoadFixtures inserts the rows of the YAML or JSON fixture files of fsys matching patterns, "*.yaml", "*.yml"
+// and "*.json" if none is given. A fixture file maps tables to their rows, which map columns to values:
+//
+//	people:
+//	  - id: 1
+//	    name: Hans
+//
+// Lists and mappings are stored as JSON. Files are loaded in the order of their names, tables in the order of
+// the file.
+func LoadFixtures(t testing.TB, q sqlx.NamedQuerier, fsys fs.FS, patterns ...string) {
+	t.Helper()
+	files, err := matchFiles(fsys, patterns, "*.yaml", "*.yml", "*.json")
+	if err != nil {
+		t.Fatalf("fixtures: %v", err)
+	}
+	for _, name := range files {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			t.Fatalf("fixtures: %v", err)
+		}
+		if err := loadFixture(q, data); err != nil {
+			t.Fatalf("fixtures %s: %v", name, err)
+		}
+	}
+}
+
+func loadFixture(q sqlx.NamedQuerier, data []byte) error {
+	// JSON is parsed as YAML, which keeps the order of the tables
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	tables := doc.Content[0]
+	if tables.Kind != yaml.MappingNode {
+		return errors.New("fixture is no mapping of tables")
+	}
+	for i := 0; i+1 < len(tables.Content); i += 2 {
+		table := tables.Content[i].Value
+		if !identifierRegex.MatchString(table) {
+			return errors.New("invalid table '" + table + "'")
+		}
+		var rows []map[string]any
+		if err := tables.Content[i+1].Decode(&rows); err != nil {
+			return fmt.Errorf("table %s: %w", table, err)
+		}
+		for _, row := range rows {
+			columns := slices.Sorted(maps.Keys(row))
+			params := make([]string, len(columns))
+			for j, column := range columns {
+				if !identifierRegex.MatchString(column) {
+					return errors.New("invalid column '" + column + "' of table " + table)
+				}
+				params[j] = ":" + column
+				switch row[column].(type) {
+				case []any, map[string]any:
+					// lists and mappings are stored as JSON rather than expanded into several parameters
+					row[column] = sqlx.JSON[any]{V: row[column]}
+				}
+			}
+			query := "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (" + strings.Join(params, ", ") + ")"
+			if _, err := q.Exec(query, row); err != nil {
+				return fmt.Errorf("table %s: %w", table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// matchFiles returns the sorted names of the files of fsys matching patterns, or defaults if there are none.
+func matchFiles(fsys fs.FS, patterns []string, defaults ...string) ([]string, error) {
+	if len(patterns) == 0 {
+		patterns = defaults
+	}
+	var names []string
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, matches...)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return strings.Compare(path.Base(a), path.Base(b))
+	})
+	return slices.Compact(names), nil
+}
+
+// AssertRowCount checks that table has want rows. Rows are read from the primary if q routes reads to replicas.
+func AssertRowCount(t testing.TB, q sqlx.NamedQuerier, table string, want int) {
+	t.Helper()
+	if !identifierRegex.MatchString(table) {
+		t.Fatalf("invalid table '%s'", table)
+	}
+	got, err := sqlx.SelectScalarContext[int](sqlx.ForcePrimary(context.Background()), q, "SELECT COUNT(*) FROM "+table)
+	if err != nil {
+		t.Fatalf("count rows of %s: %v", table, err)
+	}
+	if got != want {
+		t.Errorf("rows of %s = %d, want %d", table, got, want)
+	}
+}
+
+// AssertRow checks that the first row of query equals want, which is scanned like sqlx.Get does. Rows are read
+// from the primary if q routes reads to replicas.
+func AssertRow(t testing.TB, q sqlx.NamedQuerier, query string, want any, args ...any) {
+	t.Helper()
+	wantValue := reflect.Indirect(reflect.ValueOf(want))
+	got := reflect.New(wantValue.Type())
+	if err := q.GetContext(sqlx.ForcePrimary(context.Background()), got.Interface(), query, args...); err != nil {
+		t.Fatalf("get row: %v", err)
+	}
+	if diff := cmp.Diff(wantValue.Interface(), got.Elem().Interface()); diff != "" {
+		t.Errorf("row mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,99 @@
+package sqlxtest
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"schema/0001_people.sql": {Data: []byte("CREATE TABLE people (id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INTEGER);")},
+		"schema/0002_pets.sql": {Data: []byte(`CREATE TABLE pets (
+				id INTEGER PRIMARY KEY,
+				owner_id INTEGER NOT NULL REFERENCES people(id),
+				name TEXT NOT NULL
+			);`)},
+		"fixtures/1_people.yaml": {Data: []byte("people:\n  - id: 1\n    name: Hans\n    age: 40\n  - id: 2\n    name: Anna\n")},
+		"fixtures/2_pets.json":   {Data: []byte(`{"pets": [{"id": 1, "owner_id": 2, "name": "Rex"}]}`)},
+	}
+}
+
+type person struct {
+	ID   int64
+	Name string
+	Age  *int
+}
+
+func TestNew(t *testing.T) {
+	fsys := testFS()
+	db := New(t, WithSchema(fsys, "schema/*.sql"), WithFixtures(fsys, "fixtures/*"))
+	AssertRowCount(t, db, "people", 2)
+	AssertRowCount(t, db, "pets", 1)
+	age := 40
+	AssertRow(t, db, "SELECT * FROM people WHERE id = :id", person{ID: 1, Name: "Hans", Age: &age}, map[string]any{"id": 1})
+	AssertRow(t, db, "SELECT * FROM people WHERE id = 2", &person{ID: 2, Name: "Anna"})
+	if _, err := db.Exec("INSERT INTO pets(id, owner_id, name) VALUES(2, 3, 'Tom')"); err == nil {
+		t.Error("Exec() expected foreign key violation")
+	}
+}
+
+func TestNew_Isolated(t *testing.T) {
+	fsys := testFS()
+	for _, name := range []string{"first", "second"} {
+		t.Run(name, func(t *testing.T) {
+			db := New(t, WithSchema(fsys, "schema/*.sql"))
+			AssertRowCount(t, db, "people", 0)
+			if _, err := db.Exec("INSERT INTO people(id, name) VALUES(1, 'Hans')"); err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestTx(t *testing.T) {
+	fsys := testFS()
+	db := New(t, WithSchema(fsys, "schema/*.sql"), WithFixtures(fsys, "fixtures/1_people.yaml"))
+	t.Run("modify", func(t *testing.T) {
+		tx := Tx(t, db)
+		LoadFixtures(t, tx, fsys, "fixtures/2_pets.json")
+		if _, err := tx.Exec("DELETE FROM people WHERE id = 1"); err != nil {
+			t.Fatalf("Exec() error = %v", err)
+		}
+		AssertRowCount(t, tx, "people", 1)
+		AssertRowCount(t, tx, "pets", 1)
+	})
+	AssertRowCount(t, db, "people", 2)
+	AssertRowCount(t, db, "pets", 0)
+}
+
+func Test_loadFixture(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "empty", data: ""},
+		{name: "no mapping", data: "- a", wantErr: true},
+		{name: "invalid table", data: "people;: []", wantErr: true},
+		{name: "invalid column", data: "people: [{\"name;\": x}]", wantErr: true},
+		{name: "unknown column", data: "people: [{unknown: x}]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := New(t, WithSchema(testFS(), "schema/0001_people.sql"))
+			if err := loadFixture(db, []byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Fatalf("loadFixture() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_loadFixture_JSONValues(t *testing.T) {
+	db := New(t, WithSchema(testFS(), "schema/0001_people.sql"))
+	data := "people:\n  - id: 1\n    name: [Hans, Anna]\n  - id: 2\n    name: {first: Hans}\n"
+	if err := loadFixture(db, []byte(data)); err != nil {
+		t.Fatalf("loadFixture() error = %v", err)
+	}
+	AssertRow(t, db, "SELECT * FROM people WHERE id = 1", person{ID: 1, Name: `["Hans","Anna"]`})
+	AssertRow(t, db, "SELECT * FROM people WHERE id = 2", person{ID: 2, Name: `{"first":"Hans"}`})
+}
@@ -0,0 +1,213 @@
+package sqlx
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// stmtCache caches compiled named queries by their text and the statements prepared for the compiled queries.
+// Both are bounded by size, the least recently used entry is evicted first. Statements are closed once they are
+// evicted and no longer in use.
+type stmtCache struct {
+	db *sql.DB
+
+	mu      sync.Mutex
+	queries *lru[namedQuery]
+	stmts   *lru[*cachedStmt]
+	closed  bool
+}
+
+type cachedStmt struct {
+	stmt *sql.Stmt
+	// refs is the number of running statements, evicted statements are closed when it drops to 0
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(db *sql.DB, size int) *stmtCache {
+	return &stmtCache{db: db, queries: newLRU[namedQuery](size), stmts: newLRU[*cachedStmt](size)}
+}
+
+// compile parses query, reusing the result of former calls, see parseNamedQuery.
+func (c *stmtCache) compile(query string, backslashEscapes bool) (namedQuery, error) {
+	c.mu.Lock()
+	parsed, ok := c.queries.get(query)
+	c.mu.Unlock()
+	if ok {
+		return parsed, nil
+	}
+	parsed, err := parseNamedQuery([]byte(query), backslashEscapes)
+	if err != nil {
+		return parsed, err
+	}
+	c.mu.Lock()
+	c.queries.add(query, parsed)
+	c.mu.Unlock()
+	return parsed, nil
+}
+
+// prepare returns the statement of query for q, which is either the cached DB or one of its transactions.
+// Statements of transactions are rebound from the cached ones via sql.Tx.StmtContext, they are not prepared
+// by a transaction as this would require another connection. The returned function must be called once the
+// statement has been executed. A nil statement is returned if there is none for q or the cache is closed.
+func (c *stmtCache) prepare(ctx context.Context, q sqlQueryer, query string) (*sql.Stmt, func(), error) {
+	tx, isTx := q.(*sql.Tx)
+	if !isTx && q != c.db {
+		return nil, nil, nil
+	}
+	cached, err := c.acquire(ctx, query, !isTx)
+	if err != nil || cached == nil {
+		return nil, nil, err
+	}
+	release := func() { c.release(cached) }
+	if !isTx {
+		return cached.stmt, release, nil
+	}
+	// the statement of the transaction is closed by the transaction
+	return tx.StmtContext(ctx, cached.stmt), release, nil
+}
+
+// acquire returns the cached statement of query, which is prepared if it is missing and prepareMissing is set.
+func (c *stmtCache) acquire(ctx context.Context, query string, prepareMissing bool) (*cachedStmt, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, nil
+	}
+	if cached, ok := c.stmts.get(query); ok {
+		cached.refs++
+		c.mu.Unlock()
+		return cached, nil
+	}
+	c.mu.Unlock()
+	if !prepareMissing {
+		return nil, nil
+	}
+
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, stmt.Close()
+	}
+	if cached, ok := c.stmts.get(query); ok {
+		// prepared concurrently
+		cached.refs++
+		c.mu.Unlock()
+		return cached, stmt.Close()
+	}
+	cached := &cachedStmt{stmt: stmt, refs: 1}
+	evicted, ok := c.stmts.add(query, cached)
+	var toClose *sql.Stmt
+	if ok {
+		toClose = evicted.evict()
+	}
+	c.mu.Unlock()
+	if toClose != nil {
+		_ = toClose.Close()
+	}
+	return cached, nil
+}
+
+func (c *stmtCache) release(cached *cachedStmt) {
+	c.mu.Lock()
+	cached.refs--
+	var toClose *sql.Stmt
+	if cached.evicted && cached.refs == 0 {
+		toClose = cached.stmt
+	}
+	c.mu.Unlock()
+	if toClose != nil {
+		_ = toClose.Close()
+	}
+}
+
+// close closes the cached statements, those in use are closed when they are released.
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	c.closed = true
+	var toClose []*sql.Stmt
+	for _, cached := range c.stmts.clear() {
+		if stmt := cached.evict(); stmt != nil {
+			toClose = append(toClose, stmt)
+		}
+	}
+	c.queries.clear()
+	c.mu.Unlock()
+	var errs []error
+	for _, stmt := range toClose {
+		errs = append(errs, stmt.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// evict marks the statement as evicted and returns it if it is to be closed now.
+func (s *cachedStmt) evict() *sql.Stmt {
+	s.evicted = true
+	if s.refs == 0 {
+		return s.stmt
+	}
+	return nil
+}
+
+// lru is a map bounded by size which evicts the least recently used entry, it is not safe for concurrent use.
+type lru[V any] struct {
+	size  int
+	order *list.List // of *lruEntry, most recently used first
+	items map[string]*list.Element
+}
+
+type lruEntry[V any] struct {
+	key   string
+	value V
+}
+
+func newLRU[V any](size int) *lru[V] {
+	return &lru[V]{size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *lru[V]) get(key string) (V, bool) {
+	e, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry[V]).value, true
+}
+
+// add adds or replaces the value of key and returns the evicted value, if any.
+func (c *lru[V]) add(key string, value V) (V, bool) {
+	var evicted V
+	if e, ok := c.items[key]; ok {
+		e.Value.(*lruEntry[V]).value = value
+		c.order.MoveToFront(e)
+		return evicted, false
+	}
+	c.items[key] = c.order.PushFront(&lruEntry[V]{key: key, value: value})
+	if c.order.Len() <= c.size {
+		return evicted, false
+	}
+	oldest := c.order.Back()
+	c.order.Remove(oldest)
+	entry := oldest.Value.(*lruEntry[V])
+	delete(c.items, entry.key)
+	return entry.value, true
+}
+
+// clear removes all entries and returns their values.
+func (c *lru[V]) clear() []V {
+	values := make([]V, 0, c.order.Len())
+	for e := c.order.Front(); e != nil; e = e.Next() {
+		values = append(values, e.Value.(*lruEntry[V]).value)
+	}
+	c.order.Init()
+	clear(c.items)
+	return values
+}
@@ -0,0 +1,214 @@
+package sqlx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_lru(t *testing.T) {
+	tests := []struct {
+		name        string
+		size        int
+		add         []string
+		get         []string
+		wantEvicted []string
+		wantKeys    []string
+	}{{
+		name:     "within size",
+		size:     2,
+		add:      []string{"a", "b"},
+		wantKeys: []string{"b", "a"},
+	}, {
+		name:        "evict oldest",
+		size:        2,
+		add:         []string{"a", "b", "c"},
+		wantEvicted: []string{"a"},
+		wantKeys:    []string{"c", "b"},
+	}, {
+		name:        "get refreshes",
+		size:        2,
+		add:         []string{"a", "b"},
+		get:         []string{"a"},
+		wantEvicted: []string{"b"},
+		wantKeys:    []string{"c", "a"},
+	}, {
+		name:     "replace",
+		size:     2,
+		add:      []string{"a", "b", "a"},
+		wantKeys: []string{"a", "b"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newLRU[string](tt.size)
+			var evicted []string
+			add := func(key string) {
+				if v, ok := c.add(key, key); ok {
+					evicted = append(evicted, v)
+				}
+			}
+			for _, key := range tt.add {
+				add(key)
+			}
+			for _, key := range tt.get {
+				if _, ok := c.get(key); !ok {
+					t.Fatalf("get(%q) missing", key)
+				}
+			}
+			if len(tt.get) > 0 {
+				add("c")
+			}
+			if diff := cmp.Diff(tt.wantEvicted, evicted); diff != "" {
+				t.Errorf("evicted mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantKeys, c.clear()); diff != "" {
+				t.Errorf("keys mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_DB_StatementCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       func(db *DB) error
+		setupExpect func(m sqlmock.Sqlmock)
+	}{{
+		name: "reuse",
+		query: func(db *DB) error {
+			for _, name := range []string{"Hans", "Anna"} {
+				if _, err := db.Exec("UPDATE tab SET name = :name", map[string]any{"name": name}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectPrepare("UPDATE tab SET name = ?").WillBeClosed()
+			m.ExpectExec("UPDATE tab SET name = ?").WithArgs("Hans").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectExec("UPDATE tab SET name = ?").WithArgs("Anna").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectClose()
+		},
+	}, {
+		name: "evict",
+		query: func(db *DB) error {
+			if _, err := db.Exec("UPDATE tab SET name = :name", map[string]any{"name": "Hans"}); err != nil {
+				return err
+			}
+			var got []*testStruct
+			return db.Select(&got, "SELECT * FROM tab WHERE id = :id", map[string]any{"id": 1})
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectPrepare("UPDATE tab SET name = ?").WillBeClosed()
+			m.ExpectExec("UPDATE tab SET name = ?").WithArgs("Hans").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectPrepare("SELECT * FROM tab WHERE id = ?").WillBeClosed()
+			m.ExpectQuery("SELECT * FROM tab WHERE id = ?").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+			m.ExpectClose()
+		},
+	}, {
+		name: "transaction",
+		query: func(db *DB) error {
+			if _, err := db.Exec("UPDATE tab SET name = :name", map[string]any{"name": "Hans"}); err != nil {
+				return err
+			}
+			return db.InTx(context.Background(), func(tx *Transaction) error {
+				_, err := tx.Exec("UPDATE tab SET name = :name", map[string]any{"name": "Anna"})
+				return err
+			})
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectPrepare("UPDATE tab SET name = ?").WillBeClosed()
+			m.ExpectExec("UPDATE tab SET name = ?").WithArgs("Hans").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectBegin()
+			m.ExpectExec("UPDATE tab SET name = ?").WithArgs("Anna").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectCommit()
+			m.ExpectClose()
+		},
+	}, {
+		name: "no args",
+		query: func(db *DB) error {
+			_, err := db.Exec("DELETE FROM tab")
+			return err
+		},
+		setupExpect: func(m sqlmock.Sqlmock) {
+			m.ExpectExec("DELETE FROM tab").WillReturnResult(sqlmock.NewResult(0, 1))
+			m.ExpectClose()
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.setupExpect(mock)
+			db := NewDB(sqlDB, WithStatementCache(1))
+			if err := tt.query(db); err != nil {
+				t.Fatalf("query error = %v", err)
+			}
+			if err := db.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func Test_DB_StatementCache_Disabled(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		if err != nil {
+			t.Fatal(err)
+		}
+		mock.ExpectExec("UPDATE tab SET name = ?").WithArgs("Hans").WillReturnResult(sqlmock.NewResult(0, 1))
+		db := NewDB(sqlDB, WithStatementCache(size))
+		if db.stmts != nil {
+			t.Errorf("WithStatementCache(%d) created a cache", size)
+		}
+		if _, err := db.Exec("UPDATE tab SET name = :name", map[string]any{"name": "Hans"}); err != nil {
+			t.Fatalf("Exec() error = %v", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func Test_DB_StatementCache_SQLite(t *testing.T) {
+	db, err := Open("sqlite3", ":memory:", WithStatementCache(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.DB.SetMaxOpenConns(1)
+	createTestTable(t, db)
+	if _, err := db.Exec("INSERT INTO test_table(name) VALUES(:name)", map[string]any{"name": "Maxima"}); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	err = db.InTx(context.Background(), func(tx *Transaction) error {
+		for _, name := range []string{"Ludger", "Hans"} {
+			if _, err := tx.Exec("INSERT INTO test_table(name) VALUES(:name)", map[string]any{"name": name}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("InTx() error = %v", err)
+	}
+	for _, ids := range [][]int{{1}, {1, 2}, {2, 3}, {1}} {
+		var names []string
+		if err := db.Select(&names, "SELECT name FROM test_table WHERE id IN (:ids) ORDER BY id", map[string]any{"ids": ids}); err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		if len(names) != len(ids) {
+			t.Fatalf("Select() = %v, want %d names", names, len(ids))
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
@@ -1,23 +1,128 @@
 package sqlx
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/vloryan/go-libs/sqlx/pagination"
+)
+
+// TxFunc is run within a managed transaction.
+type TxFunc func(tx *Transaction) error
+
+// TxRunner runs functions within managed transactions. It is implemented by DB and Transaction, the latter
+// nests the function using a savepoint.
+type TxRunner interface {
+	InTx(ctx context.Context, fn TxFunc) error
+}
 
 type Transaction struct {
 	tx *sql.Tx
+	session
+	// savepoint is set for nested transactions
+	savepoint string
+	depth     int
 }
 
 func (t Transaction) Select(dest any, query string, args ...any) error {
-	return Select(t.tx, dest, query, args...)
+	return t.SelectContext(context.Background(), dest, query, args...)
+}
+
+func (t Transaction) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return selectContext(ctx, t.tx, t.session, dest, query, args...)
+}
+
+func (t Transaction) Get(dest any, query string, args ...any) error {
+	return t.GetContext(context.Background(), dest, query, args...)
+}
+
+func (t Transaction) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return getContext(ctx, t.tx, t.session, dest, query, args...)
+}
+
+// QueryContext queries rows binding the named parameters of query.
+func (t Transaction) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return queryContext(ctx, t.tx, t.session, query, args...)
 }
 
 func (t Transaction) Exec(query string, args ...any) (sql.Result, error) {
-	return Exec(t.tx, query, args...)
+	return t.ExecContext(context.Background(), query, args...)
 }
 
+func (t Transaction) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return execContext(ctx, t.tx, t.session, query, args...)
+}
+
+func (t Transaction) SelectPage(dest any, query string, page *pagination.Page, args ...any) error {
+	return SelectPage(t, dest, query, page, args...)
+}
+
+func (t Transaction) SelectPageContext(ctx context.Context, dest any, query string, page *pagination.Page, args ...any) error {
+	return SelectPageContext(ctx, t, dest, query, page, args...)
+}
+
+// Commit commits the transaction. Nested transactions release their savepoint.
 func (t Transaction) Commit() error {
+	if t.savepoint != "" {
+		if _, release, _ := t.dialect.savepoint(t.savepoint); release != "" {
+			_, err := t.tx.Exec(release)
+			return err
+		}
+		return nil
+	}
 	return t.tx.Commit()
 }
 
+// Rollback aborts the transaction. Nested transactions roll back to their savepoint.
 func (t Transaction) Rollback() error {
+	if t.savepoint != "" {
+		_, _, rollback := t.dialect.savepoint(t.savepoint)
+		_, err := t.tx.Exec(rollback)
+		return err
+	}
 	return t.tx.Rollback()
 }
+
+// InTx runs fn nested in the transaction using a savepoint. The savepoint is released if fn succeeds and
+// rolled back if fn returns an error or panics, leaving the enclosing transaction intact.
+func (t Transaction) InTx(ctx context.Context, fn TxFunc) error {
+	nested := &Transaction{
+		tx:        t.tx,
+		session:   t.session,
+		savepoint: "sqlx_sp_" + strconv.Itoa(t.depth+1),
+		depth:     t.depth + 1,
+	}
+	create, _, _ := t.dialect.savepoint(nested.savepoint)
+	if _, err := t.tx.ExecContext(ctx, create); err != nil {
+		return err
+	}
+	return runInTx(nested, fn)
+}
+
+// InTx runs fn in a transaction of q. If q already is a transaction fn is nested using a savepoint.
+func InTx(ctx context.Context, q NamedQuerier, fn TxFunc) error {
+	runner, ok := q.(TxRunner)
+	if !ok {
+		return errors.New("querier does not support transactions")
+	}
+	return runner.InTx(ctx, fn)
+}
+
+// runInTx commits tx if fn succeeds and rolls it back if fn fails or panics.
+func runInTx(tx *Transaction, fn TxFunc) error {
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
@@ -1,7 +1,11 @@
 package sqlx
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -63,3 +67,173 @@ func Test_Transaction_Select(t *testing.T) {
 		})
 	}
 }
+
+func Test_DB_InTx(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name      string
+		fn        TxFunc
+		wantNames []string
+		wantErr   error
+		wantPanic bool
+	}{{
+		name: "commit",
+		fn: func(tx *Transaction) error {
+			_, err := tx.Exec("INSERT INTO test_table(name) VALUES('Hans');")
+			return err
+		},
+		wantNames: []string{"Hans"},
+	}, {
+		name: "rollback on error",
+		fn: func(tx *Transaction) error {
+			if _, err := tx.Exec("INSERT INTO test_table(name) VALUES('Hans');"); err != nil {
+				return err
+			}
+			return errFailed
+		},
+		wantNames: []string{},
+		wantErr:   errFailed,
+	}, {
+		name: "rollback on panic",
+		fn: func(tx *Transaction) error {
+			if _, err := tx.Exec("INSERT INTO test_table(name) VALUES('Hans');"); err != nil {
+				return err
+			}
+			panic("failed")
+		},
+		wantNames: []string{},
+		wantPanic: true,
+	}, {
+		name: "nested commit",
+		fn: func(tx *Transaction) error {
+			if _, err := tx.Exec("INSERT INTO test_table(name) VALUES('Hans');"); err != nil {
+				return err
+			}
+			return tx.InTx(context.Background(), func(tx *Transaction) error {
+				_, err := tx.Exec("INSERT INTO test_table(name) VALUES('Anna');")
+				return err
+			})
+		},
+		wantNames: []string{"Hans", "Anna"},
+	}, {
+		name: "nested rollback keeps outer",
+		fn: func(tx *Transaction) error {
+			if _, err := tx.Exec("INSERT INTO test_table(name) VALUES('Hans');"); err != nil {
+				return err
+			}
+			err := InTx(context.Background(), tx, func(tx *Transaction) error {
+				if _, err := tx.Exec("INSERT INTO test_table(name) VALUES('Anna');"); err != nil {
+					return err
+				}
+				return tx.InTx(context.Background(), func(tx *Transaction) error {
+					return errFailed
+				})
+			})
+			if !errors.Is(err, errFailed) {
+				return errors.New("nested error expected")
+			}
+			return nil
+		},
+		wantNames: []string{"Hans"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := prepareDB(t)
+			func() {
+				defer func() {
+					if p := recover(); (p != nil) != tt.wantPanic {
+						t.Fatalf("InTx() panic = %v, wantPanic %v", p, tt.wantPanic)
+					}
+				}()
+				if err := db.InTx(context.Background(), tt.fn); !errors.Is(err, tt.wantErr) {
+					t.Fatalf("InTx() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}()
+			var got []*testStruct
+			if err := db.Select(&got, "SELECT * FROM test_table ORDER BY id;"); err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			gotNames := make([]string, len(got))
+			for i, p := range got {
+				gotNames[i] = p.Name
+			}
+			if diff := cmp.Diff(tt.wantNames, gotNames); diff != "" {
+				t.Errorf("InTx() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_DB_InTx_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       RetryPolicy
+		err          error
+		wantAttempts int
+	}{{
+		name:         "serialization failure",
+		policy:       RetryPolicy{MaxAttempts: 3},
+		err:          errors.New("database is locked"),
+		wantAttempts: 3,
+	}, {
+		name:         "not retryable",
+		policy:       RetryPolicy{MaxAttempts: 3},
+		err:          errors.New("constraint failed"),
+		wantAttempts: 1,
+	}, {
+		name: "custom retryable",
+		policy: RetryPolicy{MaxAttempts: 2, Backoff: func(int) time.Duration { return time.Millisecond }, Retryable: func(error) bool {
+			return true
+		}},
+		err:          errors.New("constraint failed"),
+		wantAttempts: 2,
+	}, {
+		name:         "no retries",
+		err:          errors.New("database is locked"),
+		wantAttempts: 1,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := prepareDB(t)
+			db.RetryPolicy = tt.policy
+			attempts := 0
+			err := db.InTx(context.Background(), func(tx *Transaction) error {
+				attempts++
+				return tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("InTx() error = %v, want %v", err, tt.err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("InTx() attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sql error " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestIsSerializationFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "sqlstate serialization", err: fmt.Errorf("wrapped: %w", sqlStateError("40001")), want: true},
+		{name: "sqlstate deadlock", err: sqlStateError("40P01"), want: true},
+		{name: "sqlstate other", err: sqlStateError("23505"), want: false},
+		{name: "mysql deadlock", err: errors.New("Error 1213: Deadlock found when trying to get lock"), want: true},
+		{name: "other", err: errors.New("syntax error"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSerializationFailure(tt.err); got != tt.want {
+				t.Errorf("IsSerializationFailure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package sqlx
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/vloryan/go-libs/reflectx"
+)
+
+// JSON is a column value stored as JSON document.
+type JSON[T any] struct {
+	V T
+}
+
+func (j *JSON[T]) Scan(src any) error {
+	var zero T
+	j.V = zero
+	return unmarshalJSONColumn(src, &j.V)
+}
+
+func (j JSON[T]) Value() (driver.Value, error) {
+	return marshalJSONColumn(j.V)
+}
+
+func (j JSON[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.V)
+}
+
+func (j *JSON[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &j.V)
+}
+
+// unmarshalJSONColumn unmarshals the JSON column value src into dest, NULL leaves dest unchanged.
+func unmarshalJSONColumn(src any, dest any) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(src, dest)
+	case string:
+		return json.Unmarshal([]byte(src), dest)
+	default:
+		return errors.New("unable to scan " + reflect.TypeOf(src).String() + " as JSON")
+	}
+}
+
+// marshalJSONColumn returns v as JSON column value, NULL if v is nil.
+func marshalJSONColumn(v any) (driver.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Array is a column value stored as Postgres array or, in other dialects, as JSON array. Scanning accepts both
+// representations, nested arrays are not supported.
+type Array[T any] []T
+
+func (a *Array[T]) Scan(src any) error {
+	var s string
+	switch src := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		s = string(src)
+	case string:
+		s = src
+	default:
+		return errors.New("unable to scan " + reflect.TypeOf(src).String() + " as array")
+	}
+	if !strings.HasPrefix(s, "{") {
+		*a = nil
+		return json.Unmarshal([]byte(s), (*[]T)(a))
+	}
+	elems, err := parsePostgresArray(s)
+	if err != nil {
+		return err
+	}
+	values := make(Array[T], len(elems))
+	for i, elem := range elems {
+		if err := unmarshalArrayElem(elem, &values[i]); err != nil {
+			return err
+		}
+	}
+	*a = values
+	return nil
+}
+
+// Value returns the JSON array, Postgres array literals are bound by postgresArray.
+func (a Array[T]) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return marshalJSONColumn([]T(a))
+}
+
+func (a Array[T]) postgresArray() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, v := range a {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		elem, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var s string
+		switch {
+		case string(elem) == "null":
+			sb.WriteString("NULL")
+			continue
+		case elem[0] == '"':
+			if err := json.Unmarshal(elem, &s); err != nil {
+				return nil, err
+			}
+		case elem[0] == '{' || elem[0] == '[':
+			s = string(elem)
+		default:
+			sb.Write(elem)
+			continue
+		}
+		sb.WriteByte('"')
+		sb.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String(), nil
+}
+
+// postgresArrayValuer is implemented by Array, which is bound as array literal for Postgres.
+type postgresArrayValuer interface {
+	postgresArray() (driver.Value, error)
+}
+
+type postgresArrayArg struct {
+	postgresArrayValuer
+}
+
+func (a postgresArrayArg) Value() (driver.Value, error) {
+	return a.postgresArray()
+}
+
+// dialectArgs returns args with the values bound differently by the dialect replaced.
+func dialectArgs(dialect Dialect, args []any) []any {
+	if dialect != Postgres {
+		return args
+	}
+	var converted []any
+	for i, arg := range args {
+		a, ok := arg.(postgresArrayValuer)
+		if !ok {
+			continue
+		}
+		if converted == nil {
+			converted = append([]any{}, args...)
+		}
+		converted[i] = postgresArrayArg{a}
+	}
+	if converted == nil {
+		return args
+	}
+	return converted
+}
+
+// parsePostgresArray splits a one-dimensional array literal like {a,"b c",NULL} into its elements, NULL
+// elements are nil.
+func parsePostgresArray(s string) ([]*string, error) {
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, errors.New("invalid array literal '" + s + "'")
+	}
+	s = s[1 : len(s)-1]
+	if s == "" {
+		return []*string{}, nil
+	}
+	var elems []*string
+	for i := 0; i <= len(s); {
+		var elem strings.Builder
+		quoted := i < len(s) && s[i] == '"'
+		if quoted {
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+				if i < len(s) {
+					elem.WriteByte(s[i])
+				}
+			}
+			if i >= len(s) {
+				return nil, errors.New("unterminated element in array literal at " + strconv.Itoa(i))
+			}
+			i++
+		} else {
+			for ; i < len(s) && s[i] != ','; i++ {
+				if s[i] == '{' {
+					return nil, errors.New("nested array literals are not supported")
+				}
+				elem.WriteByte(s[i])
+			}
+		}
+		if i < len(s) && s[i] != ',' {
+			return nil, errors.New("invalid array literal at " + strconv.Itoa(i))
+		}
+		i++
+		value := elem.String()
+		if !quoted && strings.EqualFold(value, "NULL") {
+			elems = append(elems, nil)
+		} else {
+			elems = append(elems, &value)
+		}
+	}
+	return elems, nil
+}
+
+// unmarshalArrayElem sets dest to the element of an array literal, which is unmarshalled as JSON value if it is
+// one and as JSON string otherwise.
+func unmarshalArrayElem(elem *string, dest any) error {
+	if elem == nil {
+		return nil
+	}
+	s := *elem
+	switch s {
+	case "t":
+		s = "true"
+	case "f":
+		s = "false"
+	}
+	if reflectx.DeRef(reflect.TypeOf(dest).Elem()).Kind() != reflect.String && json.Unmarshal([]byte(s), dest) == nil {
+		return nil
+	}
+	quoted, err := json.Marshal(*elem)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(quoted, dest)
+}
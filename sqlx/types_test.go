@@ -0,0 +1,218 @@
+package sqlx
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestArray_Scan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		want    any
+		scan    func(src any) (any, error)
+		wantErr bool
+	}{{
+		name: "postgres strings",
+		src:  []byte(`{a,"b c","d\"e\\f",NULL,"NULL"}`),
+		scan: scanArray[string],
+		want: Array[string]{"a", "b c", `d"e\f`, "", "NULL"},
+	}, {
+		name: "postgres ints",
+		src:  "{1,2,3}",
+		scan: scanArray[int],
+		want: Array[int]{1, 2, 3},
+	}, {
+		name: "postgres bools",
+		src:  "{t,f}",
+		scan: scanArray[bool],
+		want: Array[bool]{true, false},
+	}, {
+		name: "postgres pointers",
+		src:  "{1,NULL}",
+		scan: scanArray[*int],
+		want: Array[*int]{ptr(1), nil},
+	}, {
+		name: "postgres empty",
+		src:  "{}",
+		scan: scanArray[string],
+		want: Array[string]{},
+	}, {
+		name: "json",
+		src:  `["a","b"]`,
+		scan: scanArray[string],
+		want: Array[string]{"a", "b"},
+	}, {
+		name: "null",
+		src:  nil,
+		scan: scanArray[string],
+		want: Array[string](nil),
+	}, {
+		name:    "nested",
+		src:     "{{1,2},{3,4}}",
+		scan:    scanArray[int],
+		wantErr: true,
+	}, {
+		name:    "unterminated",
+		src:     `{"a}`,
+		scan:    scanArray[string],
+		wantErr: true,
+	}, {
+		name:    "unsupported",
+		src:     42,
+		scan:    scanArray[int],
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Scan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Scan() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func scanArray[T any](src any) (any, error) {
+	var a Array[T]
+	err := a.Scan(src)
+	return a, err
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestArray_Value(t *testing.T) {
+	tests := []struct {
+		name         string
+		array        postgresArrayValuer
+		wantJSON     driver.Value
+		wantPostgres driver.Value
+	}{{
+		name:         "strings",
+		array:        Array[string]{"a", "b c", `d"e\f`},
+		wantJSON:     `["a","b c","d\"e\\f"]`,
+		wantPostgres: `{"a","b c","d\"e\\f"}`,
+	}, {
+		name:         "numbers",
+		array:        Array[float64]{1, 2.5},
+		wantJSON:     `[1,2.5]`,
+		wantPostgres: `{1,2.5}`,
+	}, {
+		name:         "pointers",
+		array:        Array[*bool]{ptr(true), nil},
+		wantJSON:     `[true,null]`,
+		wantPostgres: `{true,NULL}`,
+	}, {
+		name:         "nil",
+		array:        Array[string](nil),
+		wantJSON:     nil,
+		wantPostgres: nil,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotJSON, err := tt.array.(driver.Valuer).Value()
+			if err != nil {
+				t.Fatalf("Value() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantJSON, gotJSON); diff != "" {
+				t.Errorf("Value() mismatch (-want +got):\n%s", diff)
+			}
+			gotPostgres, err := tt.array.postgresArray()
+			if err != nil {
+				t.Fatalf("postgresArray() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantPostgres, gotPostgres); diff != "" {
+				t.Errorf("postgresArray() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type document struct {
+	Title  string
+	Labels []string
+}
+
+type documentRow struct {
+	ID      int64
+	Doc     document       `db:"doc,json"`
+	Meta    *document      `db:"meta,json"`
+	Typed   JSON[document] `db:"typed"`
+	Tags    Array[string]  `db:"tags"`
+	Ignored string         `db:"-"`
+}
+
+func Test_DB_JSON(t *testing.T) {
+	db, err := Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE documents (id INTEGER PRIMARY KEY, doc TEXT, meta TEXT, typed TEXT, tags TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	rows := []*documentRow{{
+		ID:    1,
+		Doc:   document{Title: "a", Labels: []string{"x", "y"}},
+		Meta:  &document{Title: "meta"},
+		Typed: JSON[document]{V: document{Title: "typed"}},
+		Tags:  Array[string]{"t1", "t2"},
+	}, {
+		ID: 2,
+	}}
+	for _, row := range rows {
+		if _, err := db.Exec("INSERT INTO documents(id, doc, meta, typed, tags) VALUES(:id, :doc, :meta, :typed, :tags)", row); err != nil {
+			t.Fatalf("Exec() error = %v", err)
+		}
+	}
+	var meta *string
+	if err := db.Get(&meta, "SELECT meta FROM documents WHERE id = 2"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if meta != nil {
+		t.Errorf("Get() = %v, want NULL for nil JSON field", *meta)
+	}
+	var got []*documentRow
+	if err := db.Select(&got, "SELECT * FROM documents ORDER BY id"); err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	rows[1].Typed = JSON[document]{}
+	if diff := cmp.Diff(rows, got); diff != "" {
+		t.Errorf("Select() mismatch (-want +got):\n%s", diff)
+	}
+	tags, err := SelectScalar[Array[string]](db, "SELECT tags FROM documents WHERE id = 1")
+	if err != nil {
+		t.Fatalf("SelectScalar() error = %v", err)
+	}
+	if diff := cmp.Diff(Array[string]{"t1", "t2"}, tags); diff != "" {
+		t.Errorf("SelectScalar() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_DB_Array_Postgres(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectExec("UPDATE documents SET tags = $1, doc = $2 WHERE id IN ($3, $4)").
+		WithArgs(`{"a","b"}`, `{"Title":"a","Labels":null}`, 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	db := NewDB(sqlDB, WithDialect(Postgres))
+	args := map[string]any{"tags": Array[string]{"a", "b"}, "doc": JSON[document]{V: document{Title: "a"}}, "ids": []int{1, 2}}
+	if _, err := db.Exec("UPDATE documents SET tags = :tags, doc = :doc WHERE id IN (:ids)", args); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}